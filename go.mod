@@ -5,9 +5,12 @@ go 1.23.7
 toolchain go1.23.11
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/hashicorp/terraform-plugin-framework v1.15.0
+	github.com/hashicorp/terraform-plugin-go v0.27.0
 	github.com/hashicorp/terraform-plugin-log v0.9.0
 	github.com/jarcoal/httpmock v1.4.0
+	github.com/klauspost/compress v1.18.0
 	github.com/stretchr/testify v1.8.3
 	github.com/tidwall/gjson v1.14.4
 	golang.org/x/time v0.12.0
@@ -43,7 +46,6 @@ require (
 	github.com/hashicorp/terraform-exec v0.23.0 // indirect
 	github.com/hashicorp/terraform-json v0.25.0 // indirect
 	github.com/hashicorp/terraform-plugin-docs v0.22.0 // indirect
-	github.com/hashicorp/terraform-plugin-go v0.27.0 // indirect
 	github.com/hashicorp/terraform-registry-address v0.2.5 // indirect
 	github.com/hashicorp/terraform-svchost v0.1.1 // indirect
 	github.com/hashicorp/yamux v0.1.1 // indirect
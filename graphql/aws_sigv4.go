@@ -0,0 +1,534 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	awsSigV4Algorithm      = "AWS4-HMAC-SHA256"
+	awsSigV4DefaultService = "appsync"
+	awsSigV4TimeFormat     = "20060102T150405Z"
+	awsSigV4DateFormat     = "20060102"
+)
+
+// awsSigV4UnsignedHeaders are headers that may be changed by proxies or the
+// HTTP client after signing and are therefore excluded from the signature.
+var awsSigV4UnsignedHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"content-length":  true,
+	"accept-encoding": true,
+	"connection":      true,
+	"expect":          true,
+}
+
+// AWSSigV4Model describes the aws_sigv4 block
+type AWSSigV4Model struct {
+	Region       types.String `tfsdk:"region"`
+	Service      types.String `tfsdk:"service"`
+	AccessKey    types.String `tfsdk:"access_key"`
+	SecretKey    types.String `tfsdk:"secret_key"`
+	SessionToken types.String `tfsdk:"session_token"`
+	Profile      types.String `tfsdk:"profile"`
+}
+
+// awsCredentials holds the credentials used to sign requests.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is when temporary credentials expire; zero if they do not
+	Expires time.Time
+}
+
+// awsCredentialsProvider retrieves the credentials used to sign requests.
+// Retrieve is called for every request, so temporary credentials that expire
+// during a long apply are refreshed.
+type awsCredentialsProvider interface {
+	Retrieve(ctx context.Context) (awsCredentials, error)
+}
+
+// staticAWSCredentials are credentials from the aws_sigv4 block or the
+// environment, which cannot change while the provider runs.
+type staticAWSCredentials awsCredentials
+
+// Retrieve returns the credentials.
+func (c staticAWSCredentials) Retrieve(context.Context) (awsCredentials, error) {
+	return awsCredentials(c), nil
+}
+
+// sharedFileAWSCredentials reads the static keys of a profile from the shared
+// credentials and config files. The files are read again whenever they
+// change, e.g. when external tooling writes fresh session credentials.
+type sharedFileAWSCredentials struct {
+	profile         string
+	credentialsFile *cachedFile
+	configFile      *cachedFile
+}
+
+// Retrieve returns the keys of the profile from the current file contents.
+func (c *sharedFileAWSCredentials) Retrieve(context.Context) (awsCredentials, error) {
+	for _, file := range []struct {
+		file         *cachedFile
+		isConfigFile bool
+	}{{c.credentialsFile, false}, {c.configFile, true}} {
+		content, err := file.file.Read()
+		if err != nil {
+			continue
+		}
+		values := parseAWSSharedProfile(content, c.profile, file.isConfigFile)
+		if values["aws_access_key_id"] != "" && values["aws_secret_access_key"] != "" {
+			return awsCredentials{
+				AccessKeyID:     values["aws_access_key_id"],
+				SecretAccessKey: values["aws_secret_access_key"],
+				SessionToken:    values["aws_session_token"],
+			}, nil
+		}
+	}
+	return awsCredentials{}, fmt.Errorf("no static keys for profile %q in the shared credentials or config file", c.profile)
+}
+
+// processAWSCredentials runs the credential_process of a profile in the
+// shared config file, which prints temporary credentials as JSON.
+type processAWSCredentials struct {
+	command string
+}
+
+// awsProcessOutput is the output format of an AWS credential_process.
+type awsProcessOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// Retrieve runs the command and parses the credentials it prints.
+func (c *processAWSCredentials) Retrieve(ctx context.Context) (awsCredentials, error) {
+	// The AWS CLI and SDKs run credential_process through the shell
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", c.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return awsCredentials{}, fmt.Errorf("credential_process %q failed: %w: %s", c.command, err, message)
+		}
+		return awsCredentials{}, fmt.Errorf("credential_process %q failed: %w", c.command, err)
+	}
+
+	var output awsProcessOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return awsCredentials{}, fmt.Errorf("credential_process %q did not print valid JSON: %w", c.command, err)
+	}
+	if output.Version != 1 {
+		return awsCredentials{}, fmt.Errorf("credential_process %q printed unsupported Version %d, expected 1", c.command, output.Version)
+	}
+	if output.AccessKeyID == "" || output.SecretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("credential_process %q did not print AccessKeyId and SecretAccessKey", c.command)
+	}
+	expires, err := parseAbsoluteTokenExpiry(output.Expiration)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("credential_process %q printed an invalid Expiration: %w", c.command, err)
+	}
+
+	return awsCredentials{
+		AccessKeyID:     output.AccessKeyID,
+		SecretAccessKey: output.SecretAccessKey,
+		SessionToken:    output.SessionToken,
+		Expires:         expires,
+	}, nil
+}
+
+// awsCredentialsCache caches the credentials of a provider until shortly
+// before they expire, so temporary credentials are only fetched again when
+// needed.
+type awsCredentialsCache struct {
+	provider awsCredentialsProvider
+	now      func() time.Time
+
+	mu          sync.Mutex
+	credentials awsCredentials
+	valid       bool
+}
+
+// newAWSCredentialsCache wraps a credentials provider in a cache.
+func newAWSCredentialsCache(provider awsCredentialsProvider) *awsCredentialsCache {
+	return &awsCredentialsCache{provider: provider, now: time.Now}
+}
+
+// Retrieve returns the cached credentials, fetching new ones if there are
+// none or they are about to expire.
+func (c *awsCredentialsCache) Retrieve(ctx context.Context) (awsCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid && (c.credentials.Expires.IsZero() || c.now().Before(c.credentials.Expires.Add(-tokenRefreshSkew))) {
+		return c.credentials, nil
+	}
+
+	credentials, err := c.provider.Retrieve(ctx)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	c.credentials = credentials
+	c.valid = true
+	return credentials, nil
+}
+
+// awsSigV4Signer signs HTTP requests with AWS Signature Version 4.
+type awsSigV4Signer struct {
+	region      string
+	service     string
+	credentials awsCredentialsProvider
+	now         func() time.Time
+}
+
+// newAWSSigV4Signer resolves the region and credentials for the aws_sigv4 block.
+// Credentials are taken from the static attributes, then the standard AWS
+// environment variables, then the static keys or credential_process of the
+// profile in the shared credentials and config files.
+func newAWSSigV4Signer(ctx context.Context, model *AWSSigV4Model) (*awsSigV4Signer, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	profile := model.Profile.ValueString()
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	sharedCredentials := loadAWSSharedProfile(awsSharedCredentialsFile(), profile, false)
+	sharedConfig := loadAWSSharedProfile(awsSharedConfigFile(), profile, true)
+
+	region := firstNonEmpty(model.Region.ValueString(), os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), sharedConfig["region"])
+	if region == "" {
+		diags.AddError(
+			"Missing AWS region",
+			"Set `region` in the `aws_sigv4` block, the AWS_REGION environment variable, or a region in the shared AWS config file.",
+		)
+		return nil, diags
+	}
+
+	service := model.Service.ValueString()
+	if service == "" {
+		service = awsSigV4DefaultService
+	}
+
+	var credentials awsCredentialsProvider
+	switch {
+	case isSet(model.AccessKey) || isSet(model.SecretKey):
+		if !isSet(model.AccessKey) || !isSet(model.SecretKey) {
+			diags.AddError(
+				"Incomplete AWS credentials configuration",
+				"Both `access_key` and `secret_key` must be set in the `aws_sigv4` block.",
+			)
+			return nil, diags
+		}
+		credentials = staticAWSCredentials{
+			AccessKeyID:     model.AccessKey.ValueString(),
+			SecretAccessKey: model.SecretKey.ValueString(),
+			SessionToken:    model.SessionToken.ValueString(),
+		}
+	case model.Profile.ValueString() == "" && os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "":
+		credentials = staticAWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	case (sharedCredentials["aws_access_key_id"] != "" && sharedCredentials["aws_secret_access_key"] != "") ||
+		(sharedConfig["aws_access_key_id"] != "" && sharedConfig["aws_secret_access_key"] != ""):
+		credentials = &sharedFileAWSCredentials{
+			profile:         profile,
+			credentialsFile: newCachedFile(awsSharedCredentialsFile()),
+			configFile:      newCachedFile(awsSharedConfigFile()),
+		}
+	case sharedConfig["credential_process"] != "":
+		credentials = newAWSCredentialsCache(&processAWSCredentials{command: sharedConfig["credential_process"]})
+	default:
+		diags.AddError(
+			"Missing AWS credentials",
+			fmt.Sprintf("No AWS credentials were found for SigV4 signing. Set `access_key` and `secret_key`, the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables, or static keys or a credential_process for profile %q in the shared credentials or config file.", profile),
+		)
+		return nil, diags
+	}
+
+	// Retrieve the credentials once up front so errors surface immediately
+	if _, err := credentials.Retrieve(ctx); err != nil {
+		diags.AddError("AWS Credentials Error", fmt.Sprintf("failed to retrieve AWS credentials: %v", err))
+		return nil, diags
+	}
+
+	return &awsSigV4Signer{
+		region:      region,
+		service:     service,
+		credentials: credentials,
+		now:         time.Now,
+	}, diags
+}
+
+// Sign retrieves the current credentials and adds the X-Amz-Date,
+// X-Amz-Security-Token and Authorization headers to the request. body must be
+// the exact payload that will be sent.
+func (s *awsSigV4Signer) Sign(ctx context.Context, req *http.Request, body []byte) error {
+	credentials, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format(awsSigV4TimeFormat)
+	date := now.Format(awsSigV4DateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	// Send the path exactly as it is encoded for signing, so the server
+	// derives the same canonical URI
+	escapedPath, err := awsEscapePath(req.URL)
+	if err != nil {
+		return fmt.Errorf("failed to encode request path: %w", err)
+	}
+	req.URL.RawPath = escapedPath
+
+	canonicalHeaders, signedHeaders := awsCanonicalHeaders(req)
+	payloadHash := sha256.Sum256(body)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsCanonicalURI(escapedPath, s.service),
+		awsCanonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	credentialScope := strings.Join([]string{date, s.region, s.service, "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		awsSigV4Algorithm,
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, s.service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigV4Algorithm, credentials.AccessKeyID, credentialScope, signedHeaders, signature))
+	return nil
+}
+
+// awsCanonicalHeaders returns the canonical header block and the signed header list.
+func awsCanonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers["host"] = host
+
+	for name, values := range req.Header {
+		lowerName := strings.ToLower(name)
+		if awsSigV4UnsignedHeaders[lowerName] {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[lowerName] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name)
+		canonical.WriteString(":")
+		canonical.WriteString(headers[name])
+		canonical.WriteString("\n")
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+// awsEscapePath returns the request path with each segment URI-encoded with
+// the SigV4 unreserved set. Encoded slashes stay part of their segment.
+func awsEscapePath(u *url.URL) (string, error) {
+	escapedPath := u.EscapedPath()
+	if escapedPath == "" {
+		return "/", nil
+	}
+	segments := strings.Split(escapedPath, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = awsURIEncode(unescaped)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// awsCanonicalURI returns the canonical URI of a path encoded by
+// awsEscapePath. S3 signs the path as sent, while every other service expects
+// each segment to be encoded a second time.
+func awsCanonicalURI(escapedPath, service string) string {
+	if service == "s3" {
+		return escapedPath
+	}
+	segments := strings.Split(escapedPath, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsCanonicalQuery returns the query string with sorted, RFC 3986 encoded parameters.
+func awsCanonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode percent-encodes a value as required by SigV4.
+func awsURIEncode(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// awsSharedCredentialsFile returns the location of the shared credentials file.
+func awsSharedCredentialsFile() string {
+	if file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", "credentials")
+}
+
+// awsSharedConfigFile returns the location of the shared config file.
+func awsSharedConfigFile() string {
+	if file := os.Getenv("AWS_CONFIG_FILE"); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", "config")
+}
+
+// loadAWSSharedProfile reads the settings of a profile from a shared AWS INI
+// file. A missing or unreadable file yields no settings.
+func loadAWSSharedProfile(file, profile string, isConfigFile bool) map[string]string {
+	if file == "" {
+		return map[string]string{}
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return map[string]string{}
+	}
+	return parseAWSSharedProfile(content, profile, isConfigFile)
+}
+
+// parseAWSSharedProfile parses the settings of a profile from the contents of
+// a shared AWS INI file. In the config file, profiles other than default are
+// named "profile NAME".
+func parseAWSSharedProfile(content []byte, profile string, isConfigFile bool) map[string]string {
+	values := map[string]string{}
+
+	section := profile
+	if isConfigFile && profile != "default" {
+		section = "profile " + profile
+	}
+
+	inSection := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.Join(strings.Fields(line[1:len(line)-1]), " ") == section
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
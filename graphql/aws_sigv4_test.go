@@ -0,0 +1,334 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Signing vectors from the AWS SigV4 test suite.
+func TestAWSSigV4Signer_Sign(t *testing.T) {
+	signer := &awsSigV4Signer{
+		region:  "us-east-1",
+		service: "service",
+		credentials: staticAWSCredentials{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+		now: func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+
+	tests := []struct {
+		name              string
+		method            string
+		url               string
+		expectedSignature string
+	}{
+		{
+			name:              "get-vanilla",
+			method:            http.MethodGet,
+			url:               "https://example.amazonaws.com/",
+			expectedSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:              "post-vanilla",
+			method:            http.MethodPost,
+			url:               "https://example.amazonaws.com/",
+			expectedSignature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:              "get-vanilla-query-order-key-case",
+			method:            http.MethodGet,
+			url:               "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			expectedSignature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:              "get-unreserved",
+			method:            http.MethodGet,
+			url:               "https://example.amazonaws.com/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+			expectedSignature: "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			require.NoError(t, err)
+
+			require.NoError(t, signer.Sign(context.Background(), req, nil))
+
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			assert.Equal(t,
+				"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature="+tt.expectedSignature,
+				req.Header.Get("Authorization"),
+			)
+		})
+	}
+}
+
+func TestAWSCanonicalURI(t *testing.T) {
+	tests := []struct {
+		name            string
+		url             string
+		expectedPath    string
+		expectedS3      string
+		expectedService string
+	}{
+		{
+			name:            "empty path",
+			url:             "https://example.amazonaws.com",
+			expectedPath:    "/",
+			expectedS3:      "/",
+			expectedService: "/",
+		},
+		{
+			// Canonical URI of get-space and get-utf8 in the AWS SigV4 test
+			// suite, which sign the path in a single encoding
+			name:            "space and UTF-8",
+			url:             "https://example.amazonaws.com/example space/ሴ",
+			expectedPath:    "/example%20space/%E1%88%B4",
+			expectedS3:      "/example%20space/%E1%88%B4",
+			expectedService: "/example%2520space/%25E1%2588%25B4",
+		},
+		{
+			name:            "reserved characters Go leaves unescaped",
+			url:             "https://example.amazonaws.com/graphql/a!b$c@d:e+f",
+			expectedPath:    "/graphql/a%21b%24c%40d%3Ae%2Bf",
+			expectedS3:      "/graphql/a%21b%24c%40d%3Ae%2Bf",
+			expectedService: "/graphql/a%2521b%2524c%2540d%253Ae%252Bf",
+		},
+		{
+			name:            "encoded slash",
+			url:             "https://example.amazonaws.com/items/a%2Fb",
+			expectedPath:    "/items/a%2Fb",
+			expectedS3:      "/items/a%2Fb",
+			expectedService: "/items/a%252Fb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+
+			escapedPath, err := awsEscapePath(u)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPath, escapedPath)
+			assert.Equal(t, tt.expectedS3, awsCanonicalURI(escapedPath, "s3"))
+			assert.Equal(t, tt.expectedService, awsCanonicalURI(escapedPath, "appsync"))
+		})
+	}
+}
+
+func TestAWSSigV4Signer_SendsSignedPath(t *testing.T) {
+	signer := &awsSigV4Signer{
+		region:      "us-east-1",
+		service:     "appsync",
+		credentials: staticAWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+		now:         time.Now,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/graphql/a!b", nil)
+	require.NoError(t, err)
+	require.NoError(t, signer.Sign(context.Background(), req, nil))
+
+	assert.Equal(t, "/graphql/a%21b", req.URL.EscapedPath())
+	assert.Equal(t, "/graphql/a!b", req.URL.Path)
+}
+
+func TestNewAWSSigV4Signer(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+[ci]
+aws_access_key_id = AKIDCI
+aws_secret_access_key = ci-secret
+aws_session_token = ci-token
+`), 0o600))
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(`[profile ci]
+region = eu-west-1
+`), 0o600))
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	newModel := func() *AWSSigV4Model {
+		return &AWSSigV4Model{
+			Region:       types.StringNull(),
+			Service:      types.StringNull(),
+			AccessKey:    types.StringNull(),
+			SecretKey:    types.StringNull(),
+			SessionToken: types.StringNull(),
+			Profile:      types.StringNull(),
+		}
+	}
+
+	t.Run("static credentials", func(t *testing.T) {
+		model := newModel()
+		model.Region = types.StringValue("us-east-2")
+		model.AccessKey = types.StringValue("AKIDSTATIC")
+		model.SecretKey = types.StringValue("static-secret")
+
+		signer, diags := newAWSSigV4Signer(context.Background(), model)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		credentials, err := signer.credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKIDSTATIC", credentials.AccessKeyID)
+		assert.Equal(t, "us-east-2", signer.region)
+		assert.Equal(t, "appsync", signer.service)
+	})
+
+	t.Run("environment credentials", func(t *testing.T) {
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+		t.Setenv("AWS_REGION", "ap-southeast-2")
+
+		signer, diags := newAWSSigV4Signer(context.Background(), newModel())
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		credentials, err := signer.credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKIDENV", credentials.AccessKeyID)
+		assert.Equal(t, "ap-southeast-2", signer.region)
+	})
+
+	t.Run("shared profile", func(t *testing.T) {
+		model := newModel()
+		model.Profile = types.StringValue("ci")
+
+		signer, diags := newAWSSigV4Signer(context.Background(), model)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		credentials, err := signer.credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKIDCI", credentials.AccessKeyID)
+		assert.Equal(t, "ci-token", credentials.SessionToken)
+		assert.Equal(t, "eu-west-1", signer.region)
+	})
+
+	t.Run("shared profile rotated", func(t *testing.T) {
+		model := newModel()
+		model.Region = types.StringValue("us-east-1")
+
+		signer, diags := newAWSSigV4Signer(context.Background(), model)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+
+		original, err := os.ReadFile(credentialsFile)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.WriteFile(credentialsFile, original, 0o600) })
+		require.NoError(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = AKIDROTATED
+aws_secret_access_key = rotated-secret
+aws_session_token = rotated-token
+`), 0o600))
+
+		credentials, err := signer.credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKIDROTATED", credentials.AccessKeyID)
+		assert.Equal(t, "rotated-token", credentials.SessionToken)
+	})
+
+	t.Run("missing region", func(t *testing.T) {
+		_, diags := newAWSSigV4Signer(context.Background(), newModel())
+		assert.True(t, diags.HasError())
+	})
+
+	t.Run("access key without secret", func(t *testing.T) {
+		model := newModel()
+		model.Region = types.StringValue("us-east-1")
+		model.AccessKey = types.StringValue("AKIDSTATIC")
+
+		_, diags := newAWSSigV4Signer(context.Background(), model)
+		assert.True(t, diags.HasError())
+	})
+}
+
+func TestProcessAWSCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command uses cat")
+	}
+
+	dir := t.TempDir()
+	outputFile := filepath.Join(dir, "credentials.json")
+	writeOutput := func(accessKeyID string, expiration time.Time) {
+		require.NoError(t, os.WriteFile(outputFile, []byte(fmt.Sprintf(
+			`{"Version":1,"AccessKeyId":%q,"SecretAccessKey":"secret","SessionToken":"session","Expiration":%q}`,
+			accessKeyID, expiration.UTC().Format(time.RFC3339),
+		)), 0o600))
+	}
+	now := time.Now()
+	cache := newAWSCredentialsCache(&processAWSCredentials{command: "cat " + outputFile})
+	cache.now = func() time.Time { return now }
+
+	writeOutput("AKIDFIRST", now.Add(time.Hour))
+	credentials, err := cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDFIRST", credentials.AccessKeyID)
+	assert.Equal(t, "session", credentials.SessionToken)
+
+	// Valid credentials are reused without running the process again
+	writeOutput("AKIDSECOND", now.Add(2*time.Hour))
+	credentials, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDFIRST", credentials.AccessKeyID)
+
+	// Credentials about to expire are fetched again
+	now = now.Add(time.Hour - 30*time.Second)
+	credentials, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDSECOND", credentials.AccessKeyID)
+
+	_, err = (&processAWSCredentials{command: "echo '{\"Version\":2}'"}).Retrieve(context.Background())
+	assert.Error(t, err)
+	_, err = (&processAWSCredentials{command: "exit 1"}).Retrieve(context.Background())
+	assert.Error(t, err)
+}
+
+func TestExecuteSingleGraphQLRequest_SignsRequest(t *testing.T) {
+	var authorization, securityToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		securityToken = r.Header.Get("X-Amz-Security-Token")
+		_, _ = w.Write([]byte(`{"data":{"todo":{"id":"1"}}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{"X-Custom": "value"},
+		Signer: &awsSigV4Signer{
+			region:      "us-east-1",
+			service:     "appsync",
+			credentials: staticAWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"},
+			now:         time.Now,
+		},
+	}
+
+	_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { todo { id } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+
+	assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+	assert.Contains(t, authorization, "/us-east-1/appsync/aws4_request")
+	assert.Contains(t, authorization, "SignedHeaders=accept;content-type;host;x-amz-date;x-amz-security-token;x-custom,")
+	assert.Equal(t, "session", securityToken)
+}
@@ -0,0 +1,455 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	gqlerrors "github.com/kalenarndt/terraform-provider-graphql/internal/errors"
+)
+
+// Batching defaults, used when an attribute of the batching block is not set.
+const (
+	defaultBatchWindow  = 10 * time.Millisecond
+	defaultBatchMaxSize = 10
+)
+
+// Batching modes.
+const (
+	// batchingModeArray sends the queries of a batch as a JSON array.
+	batchingModeArray = "array"
+	// batchingModeAlias merges queries with the same document into one
+	// query, aliasing the top-level fields of each.
+	batchingModeAlias = "alias"
+)
+
+// BatchingModel describes the batching block
+type BatchingModel struct {
+	Mode    types.String `tfsdk:"mode"`
+	Window  types.String `tfsdk:"window"`
+	MaxSize types.Int64  `tfsdk:"max_size"`
+}
+
+// buildQueryBatcher builds the query batcher from the batching block.
+func buildQueryBatcher(b *BatchingModel) (*queryBatcher, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	mode := batchingModeArray
+	switch b.Mode.ValueString() {
+	case "", batchingModeArray:
+	case batchingModeAlias:
+		mode = batchingModeAlias
+	default:
+		diags.AddAttributeError(path.Root("batching").AtName("mode"), "Invalid Batching Configuration", fmt.Sprintf("`mode` must be either %q or %q, got %q.", batchingModeArray, batchingModeAlias, b.Mode.ValueString()))
+		return nil, diags
+	}
+
+	window := defaultBatchWindow
+	if isSet(b.Window) {
+		parsed, err := time.ParseDuration(b.Window.ValueString())
+		if err != nil || parsed <= 0 {
+			diags.AddAttributeError(path.Root("batching").AtName("window"), "Invalid Batching Configuration", fmt.Sprintf("`window` must be a positive duration (e.g., '10ms'), got %q.", b.Window.ValueString()))
+			return nil, diags
+		}
+		window = parsed
+	}
+
+	maxSize := defaultBatchMaxSize
+	if !b.MaxSize.IsNull() && !b.MaxSize.IsUnknown() {
+		if b.MaxSize.ValueInt64() < 2 {
+			diags.AddAttributeError(path.Root("batching").AtName("max_size"), "Invalid Batching Configuration", "`max_size` must be at least 2.")
+			return nil, diags
+		}
+		maxSize = int(b.MaxSize.ValueInt64())
+	}
+
+	return &queryBatcher{mode: mode, window: window, maxSize: maxSize}, diags
+}
+
+// queryBatcher coalesces queries issued within a short window into a single
+// HTTP request, and fans the results back out to the callers.
+type queryBatcher struct {
+	mode    string
+	window  time.Duration
+	maxSize int
+	// unsupported is set once the server responds to a batched request
+	// with something other than an array, after which queries are sent
+	// individually
+	unsupported atomic.Bool
+
+	mu      sync.Mutex
+	pending *queryBatch
+}
+
+// queryBatch is a set of queries sent in one request.
+type queryBatch struct {
+	operations []*batchedOperation
+	timer      *time.Timer
+}
+
+// batchedOperation is a query waiting for its batch to be sent.
+type batchedOperation struct {
+	ctx     context.Context
+	request graphQLRequest
+	done    chan batchResult
+}
+
+// batchResult is the outcome of one query in a batch.
+type batchResult struct {
+	response   *GqlQueryResponse
+	body       []byte
+	requestErr *gqlerrors.RequestError
+	diags      diag.Diagnostics
+}
+
+// batches reports whether a request is sent through the query batcher. Only
+// queries sent with POST are batched; mutations must run in order, and
+// persisted queries and file uploads need requests of their own.
+func (c *graphqlProviderConfig) batches(request graphQLRequest) bool {
+	return c.Batcher != nil &&
+		!c.Batcher.unsupported.Load() &&
+		c.PersistedQueries == nil &&
+		len(request.Uploads) == 0 &&
+		!isMutationQuery(request.Query) &&
+		c.requestMethod(request.Query) == http.MethodPost
+}
+
+// execute adds the request to the pending batch and waits for its result.
+func (b *queryBatcher) execute(ctx context.Context, request graphQLRequest, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, *gqlerrors.RequestError, diag.Diagnostics) {
+	tflog.Debug(ctx, "Queueing GraphQL request for batching", map[string]any{
+		"query":     request.Query,
+		"variables": config.redactVariables(request.Variables),
+	})
+
+	operation := &batchedOperation{ctx: ctx, request: request, done: make(chan batchResult, 1)}
+
+	b.mu.Lock()
+	if b.pending == nil {
+		batch := &queryBatch{}
+		batch.timer = time.AfterFunc(b.window, func() { b.flush(batch, config) })
+		b.pending = batch
+	}
+	batch := b.pending
+	batch.operations = append(batch.operations, operation)
+	if len(batch.operations) >= b.maxSize {
+		// Send a full batch right away
+		batch.timer.Stop()
+		b.pending = nil
+		go b.send(batch, config)
+	}
+	b.mu.Unlock()
+
+	select {
+	case result := <-operation.done:
+		return result.response, result.body, result.requestErr, result.diags
+	case <-ctx.Done():
+		var diags diag.Diagnostics
+		diags.AddError("Request Cancelled", fmt.Sprintf("stopped waiting for batched request: %v", ctx.Err()))
+		return nil, nil, nil, diags
+	}
+}
+
+// flush sends the batch when its window closes, unless it was already sent
+// because it filled up.
+func (b *queryBatcher) flush(batch *queryBatch, config *graphqlProviderConfig) {
+	b.mu.Lock()
+	if b.pending != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+
+	b.send(batch, config)
+}
+
+// send sends a batch and delivers the result of each query. The rate limiter
+// and cost throttle are waited for once per HTTP request, not per query.
+func (b *queryBatcher) send(batch *queryBatch, config *graphqlProviderConfig) {
+	operations := batch.operations
+	ctx, cancel := batchContext(operations)
+	defer cancel()
+
+	var diags diag.Diagnostics
+	if err := config.rateLimiter(false).Wait(ctx); err != nil {
+		diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for rate limiter: %v", err))
+	} else if err := config.CostThrottle.Wait(ctx); err != nil {
+		diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for query cost budget: %v", err))
+	}
+	if diags.HasError() {
+		for _, operation := range operations {
+			operation.done <- batchResult{diags: diags}
+		}
+		return
+	}
+
+	if len(operations) == 1 {
+		b.sendIndividually(operations, config)
+		return
+	}
+	if b.mode == batchingModeAlias {
+		b.sendCoalesced(ctx, operations, config)
+		return
+	}
+
+	results, supported := sendBatch(ctx, operations, config)
+	if !supported {
+		tflog.Debug(ctx, "Server rejected the batched request, sending queries individually from now on", map[string]any{
+			"operations": len(operations),
+		})
+		b.unsupported.Store(true)
+		b.sendIndividually(operations, config)
+		return
+	}
+	for i, operation := range operations {
+		operation.done <- results[i]
+	}
+}
+
+// batchContext returns the context a batch is sent with. Callers may give up
+// while waiting, but the batch is sent on behalf of all of them, so it is
+// only cancelled once every caller's context is done. The returned cancel
+// function must be called once the batch has been sent.
+func batchContext(operations []*batchedOperation) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(operations[0].ctx))
+
+	var waiting atomic.Int64
+	waiting.Store(int64(len(operations)))
+	callerDone := func() {
+		if waiting.Add(-1) == 0 {
+			cancel()
+		}
+	}
+
+	stops := make([]func() bool, 0, len(operations))
+	for _, operation := range operations {
+		// Count callers that already gave up right away, rather than in the
+		// goroutine AfterFunc starts, so the batch is never sent for nobody
+		if operation.ctx.Err() != nil {
+			callerDone()
+			continue
+		}
+		stops = append(stops, context.AfterFunc(operation.ctx, callerDone))
+	}
+
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}
+}
+
+// sendIndividually sends each query of a batch in a request of its own,
+// pacing all but the first with the rate limiter.
+func (b *queryBatcher) sendIndividually(operations []*batchedOperation, config *graphqlProviderConfig) {
+	for i, operation := range operations {
+		if i > 0 {
+			if err := config.rateLimiter(false).Wait(operation.ctx); err != nil {
+				var diags diag.Diagnostics
+				diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for rate limiter: %v", err))
+				operation.done <- batchResult{diags: diags}
+				continue
+			}
+		}
+		response, body, requestErr, diags := sendGraphQLRequest(operation.ctx, operation.request, config)
+		operation.done <- batchResult{response: response, body: body, requestErr: requestErr, diags: diags}
+	}
+}
+
+// sendBatch sends the queries as a JSON array and splits the array of
+// results. It reports false if the server does not support batching, i.e.
+// it did not respond with an array.
+func sendBatch(ctx context.Context, operations []*batchedOperation, config *graphqlProviderConfig) ([]batchResult, bool) {
+	results := make([]batchResult, len(operations))
+	fail := func(response *GqlQueryResponse, body []byte, requestErr *gqlerrors.RequestError, diags diag.Diagnostics) ([]batchResult, bool) {
+		for i := range results {
+			results[i] = batchResult{response: response, body: body, requestErr: requestErr, diags: diags}
+		}
+		return results, true
+	}
+
+	bodies := make([]map[string]interface{}, len(operations))
+	for i, operation := range operations {
+		bodies[i] = operation.request.body()
+	}
+	requestBytes, err := json.Marshal(bodies)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Request Encoding Error", fmt.Sprintf("failed to encode batched request body: %v", err))
+		return fail(nil, nil, nil, diags)
+	}
+
+	tflog.Debug(ctx, "Sending batched GraphQL request", map[string]any{
+		"url":        config.GQLServerUrl,
+		"operations": len(operations),
+	})
+
+	resp, bodyBytes, requestErr, diags := roundTrip(ctx, http.MethodPost, config.GQLServerUrl, requestBytes, "application/json; charset=utf-8", false, config)
+	if resp == nil {
+		return fail(nil, nil, requestErr, diags)
+	}
+
+	// Authentication, authorization, rate limit and server errors apply to
+	// the whole request, whatever the format of the body
+	if !isSuccessStatus(resp.StatusCode) && failsWholeBatch(resp.StatusCode) {
+		errorResponse, requestErr, errorDiags := errorStatusResult(resp, bodyBytes, config)
+		diags.Append(errorDiags...)
+		return fail(errorResponse, bodyBytes, requestErr, diags)
+	}
+
+	// A server that does not support batching cannot parse the array and
+	// responds with a single result or an error page. Servers that do
+	// respond with an array, even if some of the queries are invalid.
+	var rawResults []json.RawMessage
+	if err := json.Unmarshal(bodyBytes, &rawResults); err != nil {
+		return nil, false
+	}
+	if len(rawResults) != len(operations) {
+		diags.AddError("Batch Response Error", fmt.Sprintf("sent %d batched queries but received %d results", len(operations), len(rawResults)))
+		return fail(nil, nil, nil, diags)
+	}
+
+	for i, raw := range rawResults {
+		var queryResponse GqlQueryResponse
+		if err := json.Unmarshal(raw, &queryResponse); err != nil {
+			var resultDiags diag.Diagnostics
+			resultDiags.AddError("Response Parsing Error", fmt.Sprintf("failed to parse batched response %d: %v", i, err))
+			results[i] = batchResult{diags: resultDiags}
+			continue
+		}
+		results[i] = batchResult{response: &queryResponse, body: []byte(raw)}
+	}
+	return results, true
+}
+
+// failsWholeBatch reports whether an error status applies to every query of
+// a batch rather than to the batch format.
+func failsWholeBatch(statusCode int) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusProxyAuthRequired, http.StatusTooManyRequests:
+		return true
+	}
+	return statusCode >= 500
+}
+
+// sendCoalesced groups the queries of a batch by document and sends each
+// group as one query with aliased fields. Documents that cannot be merged,
+// and groups of one, are sent individually. Requests after the first are
+// paced with the rate limiter.
+func (b *queryBatcher) sendCoalesced(ctx context.Context, operations []*batchedOperation, config *graphqlProviderConfig) {
+	var groups [][]*batchedOperation
+	index := make(map[string]int)
+	for _, operation := range operations {
+		i, ok := index[operation.request.Query]
+		if !ok {
+			i = len(groups)
+			index[operation.request.Query] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], operation)
+	}
+
+	for i, group := range groups {
+		if i > 0 {
+			if err := config.rateLimiter(false).Wait(ctx); err != nil {
+				var diags diag.Diagnostics
+				diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for rate limiter: %v", err))
+				for _, operation := range group {
+					operation.done <- batchResult{diags: diags}
+				}
+				continue
+			}
+		}
+
+		query, ok := parseCoalescableQuery(group[0].request.Query)
+		if len(group) == 1 || !ok {
+			b.sendIndividually(group, config)
+			continue
+		}
+		results, ok := sendCoalescedGroup(ctx, query, group, config)
+		if !ok {
+			// A null root field nulled the merged data; send each query on
+			// its own so only the failing one sees the error
+			tflog.Debug(ctx, "Coalesced query returned null data, sending queries individually", map[string]any{
+				"operations": len(group),
+			})
+			if err := config.rateLimiter(false).Wait(ctx); err != nil {
+				var diags diag.Diagnostics
+				diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for rate limiter: %v", err))
+				for _, operation := range group {
+					operation.done <- batchResult{diags: diags}
+				}
+				continue
+			}
+			b.sendIndividually(group, config)
+			continue
+		}
+		for j, result := range results {
+			group[j].done <- result
+		}
+	}
+}
+
+// sendCoalescedGroup sends queries with the same document as one merged query
+// and splits the response. It reports false if the merged data is null, as
+// the response then cannot tell which queries succeeded.
+func sendCoalescedGroup(ctx context.Context, query *coalescableQuery, operations []*batchedOperation, config *graphqlProviderConfig) ([]batchResult, bool) {
+	variables := make([]map[string]interface{}, len(operations))
+	for i, operation := range operations {
+		variables[i] = operation.request.Variables
+	}
+	document, mergedVariables := query.merge(variables)
+
+	// Mask the sensitive values of every query, and redact the renamed
+	// variables at the sensitive paths
+	ctx = config.logContext(ctx, variables...)
+	mergedConfig := *config
+	mergedConfig.SensitiveVariablePaths = coalescedVariablePaths(config.SensitiveVariablePaths, len(operations))
+
+	tflog.Debug(ctx, "Sending coalesced GraphQL query", map[string]any{
+		"url":        config.GQLServerUrl,
+		"query":      document,
+		"operations": len(operations),
+	})
+
+	results := make([]batchResult, len(operations))
+	response, body, requestErr, diags := sendGraphQLRequest(ctx, graphQLRequest{Query: document, Variables: mergedVariables}, &mergedConfig)
+	if response == nil || diags.HasError() {
+		for i := range results {
+			results[i] = batchResult{response: response, body: body, requestErr: requestErr, diags: diags}
+		}
+		return results, true
+	}
+	if response.Data == nil {
+		return nil, false
+	}
+
+	bodies, err := query.split(body, len(operations))
+	if err != nil {
+		diags.AddError("Response Parsing Error", fmt.Sprintf("failed to split coalesced response: %v", err))
+		for i := range results {
+			results[i] = batchResult{diags: diags}
+		}
+		return results, true
+	}
+	for i, instanceBody := range bodies {
+		var queryResponse GqlQueryResponse
+		if err := json.Unmarshal(instanceBody, &queryResponse); err != nil {
+			var resultDiags diag.Diagnostics
+			resultDiags.AddError("Response Parsing Error", fmt.Sprintf("failed to parse coalesced response %d: %v", i, err))
+			results[i] = batchResult{diags: resultDiags}
+			continue
+		}
+		results[i] = batchResult{response: &queryResponse, body: instanceBody}
+	}
+	return results, true
+}
@@ -0,0 +1,250 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchingServer answers each query in a batch with its "id" variable, and
+// rejects batches with a single HTTP 400 error unless batching is supported.
+func newBatchingServer(t *testing.T, supported bool) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		var raw json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&raw))
+		if raw[0] == '[' {
+			if !supported {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors":[{"message":"Operation batching is not supported"}]}`))
+				return
+			}
+			var batch []graphQLBody
+			require.NoError(t, json.Unmarshal(raw, &batch))
+			results := make([]string, len(batch))
+			for i, body := range batch {
+				results[i] = fmt.Sprintf(`{"data":{"node":{"id":%q}}}`, body.Variables["id"])
+			}
+			_, _ = w.Write([]byte("[" + strings.Join(results, ",") + "]"))
+			return
+		}
+
+		var body graphQLBody
+		require.NoError(t, json.Unmarshal(raw, &body))
+		_, _ = fmt.Fprintf(w, `{"data":{"node":{"id":%q}}}`, body.Variables["id"])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// graphQLBody is a decoded GraphQL request body.
+type graphQLBody struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// executeConcurrently runs a query for each ID at the same time and returns
+// the ID each one received.
+func executeConcurrently(t *testing.T, config *graphqlProviderConfig, ids []string) []string {
+	received := make([]string, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			resp, body, diags := executeGraphQLRequestFramework(context.Background(), "query($id: ID!) { node(id: $id) { id } }", map[string]interface{}{"id": id}, config)
+			if !assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags) {
+				return
+			}
+			assert.JSONEq(t, fmt.Sprintf(`{"data":{"node":{"id":%q}}}`, id), string(body))
+			received[i] = resp.Data["node"].(map[string]interface{})["id"].(string)
+		}(i, id)
+	}
+	wg.Wait()
+	return received
+}
+
+func TestQueryBatcher_Batches(t *testing.T) {
+	server, requests := newBatchingServer(t, true)
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Batcher:        &queryBatcher{window: 100 * time.Millisecond, maxSize: 3},
+	}
+
+	ids := []string{"1", "2", "3"}
+	assert.Equal(t, ids, executeConcurrently(t, config, ids))
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests), "a full batch is sent in one request")
+
+	// Mutations are never batched
+	_, _, diags := executeGraphQLRequestFramework(context.Background(), "mutation($id: ID!) { touch(id: $id) { id } }", map[string]interface{}{"id": "4"}, config)
+	require.False(t, diags.HasError())
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestQueryBatcher_WindowFlush(t *testing.T) {
+	server, requests := newBatchingServer(t, true)
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Batcher:        &queryBatcher{window: 20 * time.Millisecond, maxSize: 10},
+	}
+
+	ids := []string{"a", "b"}
+	assert.Equal(t, ids, executeConcurrently(t, config, ids))
+	assert.LessOrEqual(t, atomic.LoadInt32(requests), int32(2))
+}
+
+func TestQueryBatcher_Unsupported(t *testing.T) {
+	server, requests := newBatchingServer(t, false)
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Batcher:        &queryBatcher{window: 100 * time.Millisecond, maxSize: 2},
+	}
+
+	ids := []string{"1", "2"}
+	assert.Equal(t, ids, executeConcurrently(t, config, ids))
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests), "the rejected batch is followed by individual requests")
+	assert.True(t, config.Batcher.unsupported.Load())
+
+	assert.Equal(t, []string{"3"}, executeConcurrently(t, config, []string{"3"}))
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+}
+
+func TestQueryBatcher_Cancellation(t *testing.T) {
+	request := graphQLRequest{Query: "query($id: ID!) { node(id: $id) { id } }", Variables: map[string]interface{}{"id": "1"}}
+
+	t.Run("all callers cancelled", func(t *testing.T) {
+		server, requests := newBatchingServer(t, true)
+		config := &graphqlProviderConfig{
+			GQLServerUrl:   server.URL,
+			RequestHeaders: map[string]interface{}{},
+			Batcher:        &queryBatcher{window: 20 * time.Millisecond, maxSize: 10},
+		}
+
+		for i := 0; i < 2; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, _, _, diags := config.Batcher.execute(ctx, request, config)
+			require.True(t, diags.HasError())
+			assert.Equal(t, "Request Cancelled", diags[0].Summary())
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(0), atomic.LoadInt32(requests), "a batch nobody waits for is not sent")
+	})
+
+	t.Run("some callers cancelled", func(t *testing.T) {
+		server, requests := newBatchingServer(t, true)
+		config := &graphqlProviderConfig{
+			GQLServerUrl:   server.URL,
+			RequestHeaders: map[string]interface{}{},
+			Batcher:        &queryBatcher{window: 20 * time.Millisecond, maxSize: 10},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, _, diags := config.Batcher.execute(ctx, request, config)
+		require.True(t, diags.HasError())
+
+		resp, _, _, diags := config.Batcher.execute(context.Background(), request, config)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		assert.Equal(t, "1", resp.Data["node"].(map[string]interface{})["id"])
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	})
+}
+
+func TestQueryBatcher_ErrorStatusKeepsBatching(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErrors []bool
+	}{
+		{
+			name:       "forbidden",
+			status:     http.StatusForbidden,
+			body:       `{"errors":[{"message":"forbidden"}]}`,
+			wantErrors: []bool{true, true},
+		},
+		{
+			name:       "one invalid query",
+			status:     http.StatusBadRequest,
+			body:       `[{"data":{"node":{"id":"1"}}},{"errors":[{"message":"Variable \"$id\" is invalid"}]}]`,
+			wantErrors: []bool{false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := &graphqlProviderConfig{
+				GQLServerUrl:   server.URL,
+				RequestHeaders: map[string]interface{}{},
+				Batcher:        &queryBatcher{window: 100 * time.Millisecond, maxSize: 2},
+			}
+
+			gotErrors := make([]bool, 2)
+			var wg sync.WaitGroup
+			for i := range gotErrors {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					// Send the queries in order so the results line up
+					time.Sleep(time.Duration(i) * 20 * time.Millisecond)
+					resp, _, diags := executeGraphQLRequestFramework(context.Background(), "query($id: ID!) { node(id: $id) { id } }", map[string]interface{}{"id": fmt.Sprint(i + 1)}, config)
+					gotErrors[i] = diags.HasError() || len(resp.Errors) > 0
+				}(i)
+			}
+			wg.Wait()
+
+			assert.Equal(t, tt.wantErrors, gotErrors)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "the batch is not resent individually")
+			assert.False(t, config.Batcher.unsupported.Load())
+		})
+	}
+}
+
+func TestBuildQueryBatcher(t *testing.T) {
+	batcher, diags := buildQueryBatcher(&BatchingModel{Mode: types.StringNull(), Window: types.StringNull(), MaxSize: types.Int64Null()})
+	require.False(t, diags.HasError())
+	assert.Equal(t, defaultBatchWindow, batcher.window)
+	assert.Equal(t, defaultBatchMaxSize, batcher.maxSize)
+	assert.Equal(t, batchingModeArray, batcher.mode)
+
+	tests := []struct {
+		name  string
+		model BatchingModel
+	}{
+		{name: "invalid window", model: BatchingModel{Mode: types.StringNull(), Window: types.StringValue("soon"), MaxSize: types.Int64Null()}},
+		{name: "zero window", model: BatchingModel{Mode: types.StringNull(), Window: types.StringValue("0s"), MaxSize: types.Int64Null()}},
+		{name: "invalid mode", model: BatchingModel{Mode: types.StringValue("merge"), Window: types.StringNull(), MaxSize: types.Int64Null()}},
+		{name: "batch of one", model: BatchingModel{Mode: types.StringNull(), Window: types.StringNull(), MaxSize: types.Int64Value(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, diags := buildQueryBatcher(&tt.model)
+			assert.True(t, diags.HasError())
+		})
+	}
+}
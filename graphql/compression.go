@@ -0,0 +1,168 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// defaultMaxResponseSize is the largest response body read when
+	// max_response_size is not set.
+	defaultMaxResponseSize = 64 << 20
+	// minCompressedRequestSize is the smallest request body worth compressing.
+	minCompressedRequestSize = 1024
+)
+
+// bodySettings controls how request and response bodies are encoded.
+type bodySettings struct {
+	CompressRequests bool
+	MaxResponseSize  int64
+}
+
+// defaultBodySettings is used when the http block does not configure them.
+var defaultBodySettings = bodySettings{
+	MaxResponseSize: defaultMaxResponseSize,
+}
+
+// buildBodySettings returns the body encoding settings of the http block.
+func buildBodySettings(h *HTTPModel) (bodySettings, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	settings := defaultBodySettings
+	if h == nil {
+		return settings, diags
+	}
+
+	if !h.CompressRequests.IsNull() && !h.CompressRequests.IsUnknown() {
+		settings.CompressRequests = h.CompressRequests.ValueBool()
+	}
+	if !h.MaxResponseSize.IsNull() && !h.MaxResponseSize.IsUnknown() {
+		if h.MaxResponseSize.ValueInt64() < 1 {
+			diags.AddAttributeError(path.Root("http").AtName("max_response_size"), "Invalid HTTP Configuration", "`max_response_size` must be at least 1 byte.")
+		}
+		settings.MaxResponseSize = h.MaxResponseSize.ValueInt64()
+	}
+
+	return settings, diags
+}
+
+// gzipBody compresses a request body.
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// responseTooLargeError is returned when a response body exceeds
+// max_response_size.
+type responseTooLargeError struct {
+	Limit int64
+}
+
+func (e *responseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the maximum of %d bytes", e.Limit)
+}
+
+// unsupportedEncodingError is returned when a response is compressed with an
+// encoding the provider cannot decode.
+type unsupportedEncodingError struct {
+	Encoding string
+}
+
+func (e *unsupportedEncodingError) Error() string {
+	return fmt.Sprintf("response is encoded with %q, but only gzip, deflate, br and zstd are supported", e.Encoding)
+}
+
+// readResponseBody decodes and reads a response body, reading at most limit
+// bytes after decompression so neither large nor highly compressed responses
+// can exhaust memory.
+func readResponseBody(resp *http.Response, limit int64) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gzip response: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "deflate":
+		zlibReader, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode deflate response: %w", err)
+		}
+		defer zlibReader.Close()
+		reader = zlibReader
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	case "zstd":
+		// A single decoder goroutine is plenty for one response, and the
+		// window limit keeps a hostile frame header from allocating more
+		// than the response itself may hold
+		maxWindow := uint64(zstd.MaxWindowSize)
+		if limit < zstd.MaxWindowSize {
+			maxWindow = uint64(max(limit, zstd.MinWindowSize))
+		}
+		zstdReader, err := zstd.NewReader(resp.Body, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxWindow))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode zstd response: %w", err)
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	default:
+		// e.g. compress. Some proxies re-encode responses regardless of
+		// Accept-Encoding
+		return nil, &unsupportedEncodingError{Encoding: encoding}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+		return nil, &responseTooLargeError{Limit: limit}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, &responseTooLargeError{Limit: limit}
+	}
+	return body, nil
+}
+
+// readResponse reads a response body from endpoint with readResponseBody and
+// the configured max_response_size, describing failures as diagnostics.
+func (c *graphqlProviderConfig) readResponse(resp *http.Response, endpoint string) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	body, err := readResponseBody(resp, c.bodySettings().MaxResponseSize)
+	if err != nil {
+		var tooLarge *responseTooLargeError
+		var unsupported *unsupportedEncodingError
+		switch {
+		case errors.As(err, &tooLarge):
+			diags.AddError("Response Too Large", fmt.Sprintf("the response from %s exceeds `max_response_size` (%d bytes). Request fewer results, e.g. with pagination, or raise `max_response_size` in the `http` block.", endpoint, tooLarge.Limit))
+		case errors.As(err, &unsupported):
+			diags.AddError("Unsupported Response Encoding", fmt.Sprintf("the response from %s is encoded with %q, which the provider cannot decode. Only gzip, deflate, br and zstd are supported; check for a proxy that re-encodes responses.", endpoint, unsupported.Encoding))
+		default:
+			diags.AddError("Response Reading Error", fmt.Sprintf("failed to read response body: %v", err))
+		}
+		return nil, diags
+	}
+	return body, diags
+}
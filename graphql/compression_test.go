@@ -0,0 +1,243 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBodySettings(t *testing.T) {
+	settings, diags := buildBodySettings(nil)
+	require.False(t, diags.HasError())
+	assert.Equal(t, defaultBodySettings, settings)
+
+	model := newHTTPTestModel()
+	model.CompressRequests = types.BoolValue(true)
+	model.MaxResponseSize = types.Int64Value(1024)
+	settings, diags = buildBodySettings(model)
+	require.False(t, diags.HasError())
+	assert.Equal(t, bodySettings{CompressRequests: true, MaxResponseSize: 1024}, settings)
+
+	model.MaxResponseSize = types.Int64Value(0)
+	_, diags = buildBodySettings(model)
+	assert.True(t, diags.HasError())
+}
+
+func TestSendGraphQLRequest_Compression(t *testing.T) {
+	largeValue := strings.Repeat("configuration ", 200)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "gzip, br, zstd", r.Header.Get("Accept-Encoding"))
+
+		reader, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(reader).Decode(&body))
+		assert.Equal(t, largeValue, body["variables"].(map[string]interface{})["document"])
+
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		_, _ = writer.Write([]byte(`{"data":{"saved":true}}`))
+		_ = writer.Close()
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Body:           &bodySettings{CompressRequests: true, MaxResponseSize: defaultMaxResponseSize},
+	}
+	resp, _, _, diags := executeSingleGraphQLRequest(context.Background(), "mutation($document: String!) { save(document: $document) }", map[string]interface{}{"document": largeValue}, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, map[string]interface{}{"saved": true}, resp.Data)
+}
+
+func TestSendGraphQLRequest_SmallBodiesUncompressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Body:           &bodySettings{CompressRequests: true, MaxResponseSize: defaultMaxResponseSize},
+	}
+	_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { ok }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+}
+
+func TestSendGraphQLRequest_ResponseErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentEncoding string
+		body            string
+		maxResponseSize int64
+		expectedSummary string
+	}{
+		{
+			name:            "response too large",
+			body:            `{"data":{"items":["` + strings.Repeat("a", 200) + `"]}}`,
+			maxResponseSize: 100,
+			expectedSummary: "Response Too Large",
+		},
+		{
+			name:            "unsupported encoding",
+			contentEncoding: "compress",
+			body:            "not decodable",
+			maxResponseSize: defaultMaxResponseSize,
+			expectedSummary: "Unsupported Response Encoding",
+		},
+		{
+			name:            "corrupt zstd response",
+			contentEncoding: "zstd",
+			body:            "not decodable",
+			maxResponseSize: defaultMaxResponseSize,
+			expectedSummary: "Response Reading Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentEncoding != "" {
+					w.Header().Set("Content-Encoding", tt.contentEncoding)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := &graphqlProviderConfig{
+				GQLServerUrl:   server.URL,
+				RequestHeaders: map[string]interface{}{},
+				Body:           &bodySettings{MaxResponseSize: tt.maxResponseSize},
+			}
+			_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { items }", nil, config)
+			require.True(t, diags.HasError())
+			assert.Equal(t, tt.expectedSummary, diags[0].Summary())
+		})
+	}
+}
+
+func TestReadResponseBody_Encodings(t *testing.T) {
+	body := []byte(`{"data":{"items":["` + strings.Repeat("a", 4096) + `"]}}`)
+
+	encoders := map[string]func(io.Writer) io.WriteCloser{
+		"":        func(w io.Writer) io.WriteCloser { return nopWriteCloser{w} },
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"zstd": func(w io.Writer) io.WriteCloser {
+			encoder, err := zstd.NewWriter(w)
+			require.NoError(t, err)
+			return encoder
+		},
+	}
+
+	for encoding, newWriter := range encoders {
+		t.Run("encoding "+encoding, func(t *testing.T) {
+			var encoded bytes.Buffer
+			writer := newWriter(&encoded)
+			_, err := writer.Write(body)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			newResponse := func() *http.Response {
+				resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(encoded.Bytes()))}
+				if encoding != "" {
+					resp.Header.Set("Content-Encoding", encoding)
+				}
+				return resp
+			}
+
+			decoded, err := readResponseBody(newResponse(), defaultMaxResponseSize)
+			require.NoError(t, err)
+			assert.Equal(t, body, decoded)
+
+			// The limit applies to the decompressed body
+			_, err = readResponseBody(newResponse(), 1024)
+			var tooLarge *responseTooLargeError
+			assert.ErrorAs(t, err, &tooLarge)
+		})
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestLoginResponses_RespectMaxResponseSize(t *testing.T) {
+	large := `{"access_token":"` + strings.Repeat("a", 200) + `","csrf":"x"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(large))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Body:           &bodySettings{MaxResponseSize: 100},
+	}
+	p := &GraphqlProvider{}
+
+	tests := []struct {
+		name  string
+		login func() diag.Diagnostics
+	}{
+		{
+			name: "client credentials",
+			login: func() diag.Diagnostics {
+				_, _, diags := p.performClientCredentialsLogin(context.Background(), config, newClientCredentialsModel(server.URL, oauth2AuthStyleHeader))
+				return diags
+			},
+		},
+		{
+			name: "REST OAuth2",
+			login: func() diag.Diagnostics {
+				_, _, diags := p.performRestOAuth2Login(context.Background(), config, GraphqlProviderModel{
+					OAuth2RestURL:       types.StringValue(server.URL),
+					OAuth2RestBody:      types.StringValue("grant_type=client_credentials"),
+					OAuth2RestTokenPath: types.StringValue("access_token"),
+				})
+				return diags
+			},
+		},
+		{
+			name: "CSRF token",
+			login: func() diag.Diagnostics {
+				_, diags := fetchCSRFToken(context.Background(), config, &SessionLoginModel{
+					CSRFURL:        types.StringValue(server.URL),
+					CSRFCookieName: types.StringNull(),
+					CSRFTokenPath:  types.StringValue("csrf"),
+				})
+				return diags
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := tt.login()
+			require.True(t, diags.HasError())
+			assert.Equal(t, "Response Too Large", diags[0].Summary())
+		})
+	}
+}
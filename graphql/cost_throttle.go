@@ -0,0 +1,209 @@
+package graphql
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/tidwall/gjson"
+)
+
+// CostThrottleModel describes the cost_throttle block
+type CostThrottleModel struct {
+	CostPath        types.String  `tfsdk:"cost_path"`
+	RemainingPath   types.String  `tfsdk:"remaining_path"`
+	MaximumPath     types.String  `tfsdk:"maximum_path"`
+	RestoreRatePath types.String  `tfsdk:"restore_rate_path"`
+	RestoreRate     types.Float64 `tfsdk:"restore_rate"`
+	ResetAtPath     types.String  `tfsdk:"reset_at_path"`
+	MinRemaining    types.Float64 `tfsdk:"min_remaining"`
+}
+
+// validateCostThrottle checks that the cost_throttle block is complete.
+func validateCostThrottle(ct *CostThrottleModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !isSet(ct.RemainingPath) {
+		diags.AddError(
+			"Incomplete cost throttle configuration",
+			"`remaining_path` must be set in the `cost_throttle` block.",
+		)
+	}
+
+	if !ct.RestoreRate.IsNull() && !ct.RestoreRate.IsUnknown() && ct.RestoreRate.ValueFloat64() < 0 {
+		diags.AddError(
+			"Invalid cost throttle configuration",
+			"`restore_rate` must not be negative.",
+		)
+	}
+
+	if !ct.MinRemaining.IsNull() && !ct.MinRemaining.IsUnknown() && ct.MinRemaining.ValueFloat64() < 0 {
+		diags.AddError(
+			"Invalid cost throttle configuration",
+			"`min_remaining` must not be negative.",
+		)
+	}
+
+	return diags
+}
+
+// costThrottle paces requests so the query cost budget reported by the server
+// (e.g., Shopify's extensions.cost.throttleStatus or GitHub's rateLimit
+// object) is not exhausted. The cost of the previous request is used as the
+// estimate for the next one.
+type costThrottle struct {
+	costPath        string
+	remainingPath   string
+	maximumPath     string
+	restoreRatePath string
+	resetAtPath     string
+	minRemaining    float64
+
+	mu          sync.Mutex
+	known       bool
+	remaining   float64
+	maximum     float64
+	restoreRate float64
+	resetAt     time.Time
+	lastCost    float64
+	observedAt  time.Time
+}
+
+// newCostThrottle builds a throttle from the cost_throttle block.
+func newCostThrottle(ct *CostThrottleModel) *costThrottle {
+	return &costThrottle{
+		costPath:        ct.CostPath.ValueString(),
+		remainingPath:   ct.RemainingPath.ValueString(),
+		maximumPath:     ct.MaximumPath.ValueString(),
+		restoreRatePath: ct.RestoreRatePath.ValueString(),
+		resetAtPath:     ct.ResetAtPath.ValueString(),
+		minRemaining:    ct.MinRemaining.ValueFloat64(),
+		restoreRate:     ct.RestoreRate.ValueFloat64(),
+	}
+}
+
+// available returns the budget expected to be available at now, accounting
+// for the points restored since the last observation (or still to be
+// restored, if another request has reserved budget ahead of now). Callers
+// hold mu.
+func (c *costThrottle) available(now time.Time) float64 {
+	available := c.remaining
+	if c.restoreRate > 0 {
+		available += c.restoreRate * now.Sub(c.observedAt).Seconds()
+	}
+	if c.maximum > 0 {
+		available = math.Min(available, c.maximum)
+	}
+	return available
+}
+
+// reserve returns how long to wait before the next request can be sent
+// within budget, and deducts its estimated cost from the remaining budget.
+func (c *costThrottle) reserve(now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.known {
+		return 0
+	}
+
+	available := c.available(now)
+	needed := c.lastCost + c.minRemaining
+
+	var wait time.Duration
+	if available < needed {
+		switch {
+		case c.restoreRate > 0:
+			wait = time.Duration((needed - available) / c.restoreRate * float64(time.Second))
+			available = needed
+		case c.resetAt.After(now):
+			// Without a restore rate the budget only comes back when it resets
+			wait = c.resetAt.Sub(now)
+			available = math.Max(c.maximum, needed)
+		}
+	}
+
+	// Deduct the estimate so concurrent requests do not all spend the same budget
+	c.remaining = available - c.lastCost
+	c.observedAt = now.Add(wait)
+	return wait
+}
+
+// Wait blocks until the next request fits in the cost budget or the context
+// is cancelled.
+func (c *costThrottle) Wait(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+
+	wait := c.reserve(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+
+	tflog.Debug(ctx, "Query cost budget nearly exhausted, waiting", map[string]any{
+		"wait": wait,
+	})
+	return sleepContext(ctx, wait)
+}
+
+// Observe records the cost and remaining budget reported in a response body.
+func (c *costThrottle) Observe(ctx context.Context, body []byte, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	remaining := gjson.GetBytes(body, c.remainingPath)
+	if !remaining.Exists() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.known = true
+	c.remaining = remaining.Float()
+	c.observedAt = now
+	if c.costPath != "" {
+		if cost := gjson.GetBytes(body, c.costPath); cost.Exists() {
+			c.lastCost = cost.Float()
+		}
+	}
+	if c.maximumPath != "" {
+		if maximum := gjson.GetBytes(body, c.maximumPath); maximum.Exists() {
+			c.maximum = maximum.Float()
+		}
+	}
+	if c.restoreRatePath != "" {
+		if restoreRate := gjson.GetBytes(body, c.restoreRatePath); restoreRate.Exists() {
+			c.restoreRate = restoreRate.Float()
+		}
+	}
+	if c.resetAtPath != "" {
+		if resetAt := gjson.GetBytes(body, c.resetAtPath); resetAt.Exists() {
+			c.resetAt = parseResetAt(resetAt)
+		}
+	}
+
+	tflog.Debug(ctx, "Observed query cost", map[string]any{
+		"cost":        c.lastCost,
+		"remaining":   c.remaining,
+		"restoreRate": c.restoreRate,
+	})
+}
+
+// parseResetAt parses a budget reset time given as an RFC 3339 timestamp or
+// as Unix seconds.
+func parseResetAt(value gjson.Result) time.Time {
+	if value.Type == gjson.Number {
+		return time.Unix(value.Int(), 0)
+	}
+	if resetAt, err := time.Parse(time.RFC3339, value.String()); err == nil {
+		return resetAt
+	}
+	return time.Time{}
+}
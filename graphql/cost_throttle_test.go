@@ -0,0 +1,126 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newShopifyCostThrottle returns a throttle reading Shopify's cost extension.
+func newShopifyCostThrottle() *costThrottle {
+	return newCostThrottle(&CostThrottleModel{
+		CostPath:        types.StringValue("extensions.cost.requestedQueryCost"),
+		RemainingPath:   types.StringValue("extensions.cost.throttleStatus.currentlyAvailable"),
+		MaximumPath:     types.StringValue("extensions.cost.throttleStatus.maximumAvailable"),
+		RestoreRatePath: types.StringValue("extensions.cost.throttleStatus.restoreRate"),
+		RestoreRate:     types.Float64Null(),
+		ResetAtPath:     types.StringNull(),
+		MinRemaining:    types.Float64Null(),
+	})
+}
+
+func TestCostThrottle_RestoreRate(t *testing.T) {
+	now := time.Now()
+	throttle := newShopifyCostThrottle()
+
+	assert.Zero(t, throttle.reserve(now), "nothing is known before the first response")
+
+	throttle.Observe(context.Background(), []byte(`{
+		"data": {},
+		"extensions": {"cost": {
+			"requestedQueryCost": 50,
+			"throttleStatus": {"maximumAvailable": 1000, "currentlyAvailable": 60, "restoreRate": 50}
+		}}
+	}`), now)
+
+	assert.Zero(t, throttle.reserve(now), "the budget covers the next request")
+	assert.Equal(t, 800*time.Millisecond, throttle.reserve(now), "the next request waits for 40 points to be restored")
+	assert.Equal(t, 1800*time.Millisecond, throttle.reserve(now), "reservations queue behind each other")
+}
+
+func TestCostThrottle_ResetAt(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	throttle := newCostThrottle(&CostThrottleModel{
+		CostPath:        types.StringValue("data.rateLimit.cost"),
+		RemainingPath:   types.StringValue("data.rateLimit.remaining"),
+		MaximumPath:     types.StringValue("data.rateLimit.limit"),
+		RestoreRatePath: types.StringNull(),
+		RestoreRate:     types.Float64Null(),
+		ResetAtPath:     types.StringValue("data.rateLimit.resetAt"),
+		MinRemaining:    types.Float64Value(100),
+	})
+
+	throttle.Observe(context.Background(), []byte(`{"data": {"rateLimit": {"cost": 1, "limit": 5000, "remaining": 50, "resetAt": "`+now.Add(time.Hour).UTC().Format(time.RFC3339)+`"}}}`), now)
+
+	assert.Equal(t, time.Hour, throttle.reserve(now), "min_remaining is kept in reserve until the budget resets")
+	assert.Zero(t, throttle.reserve(now.Add(time.Hour)), "the budget is full after the reset")
+}
+
+func TestCostThrottle_PacesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"ok":true},"extensions":{"cost":{"requestedQueryCost":10,"throttleStatus":{"maximumAvailable":100,"currentlyAvailable":0,"restoreRate":100}}}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		CostThrottle:   newShopifyCostThrottle(),
+	}
+
+	queryResponse, _, diags := executeGraphQLRequestFramework(context.Background(), "query { ok }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.NotNil(t, queryResponse.Extensions["cost"])
+
+	start := time.Now()
+	_, _, diags = executeGraphQLRequestFramework(context.Background(), "query { ok }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "the second request waits for the budget to be restored")
+}
+
+func TestValidateCostThrottle(t *testing.T) {
+	tests := []struct {
+		name        string
+		model       *CostThrottleModel
+		expectError bool
+	}{
+		{
+			name: "remaining path only",
+			model: &CostThrottleModel{
+				RemainingPath: types.StringValue("data.rateLimit.remaining"),
+				RestoreRate:   types.Float64Null(),
+				MinRemaining:  types.Float64Null(),
+			},
+		},
+		{
+			name: "missing remaining path",
+			model: &CostThrottleModel{
+				RemainingPath: types.StringNull(),
+				RestoreRate:   types.Float64Null(),
+				MinRemaining:  types.Float64Null(),
+			},
+			expectError: true,
+		},
+		{
+			name: "negative restore rate",
+			model: &CostThrottleModel{
+				RemainingPath: types.StringValue("data.rateLimit.remaining"),
+				RestoreRate:   types.Float64Value(-1),
+				MinRemaining:  types.Float64Null(),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectError, validateCostThrottle(tt.model).HasError())
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/tidwall/gjson"
+)
+
+// credentialProcessTokenPaths are the JSON paths checked, in order, for the
+// token in the output of a credential process. status.token matches the
+// kubectl ExecCredential format.
+var credentialProcessTokenPaths = []string{"token", "access_token", "status.token"}
+
+// credentialProcessExpiryPaths are the JSON paths checked, in order, for the
+// token expiry. expires_in is relative to now; the others are Unix or RFC 3339
+// timestamps. Expiration matches the AWS credential_process format.
+var credentialProcessExpiryPaths = []struct {
+	path     string
+	relative bool
+}{
+	{path: "expires_at"},
+	{path: "expiration"},
+	{path: "Expiration"},
+	{path: "expires_in", relative: true},
+	{path: "status.expirationTimestamp"},
+}
+
+// CredentialProcessModel describes the credential_process block
+type CredentialProcessModel struct {
+	Command types.String `tfsdk:"command"`
+	Args    types.List   `tfsdk:"args"`
+}
+
+// validateCredentialProcess checks that the credential_process block is complete.
+func validateCredentialProcess(cp *CredentialProcessModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !isSet(cp.Command) {
+		diags.AddError(
+			"Incomplete credential process configuration",
+			"`command` must be set in the `credential_process` block.",
+		)
+	}
+
+	return diags
+}
+
+// runCredentialProcess executes the configured command and parses the token
+// and its expiry from the JSON it writes to stdout.
+func (p *GraphqlProvider) runCredentialProcess(ctx context.Context, cp *CredentialProcessModel) (string, time.Time, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var args []string
+	if !cp.Args.IsNull() && !cp.Args.IsUnknown() {
+		diags.Append(cp.Args.ElementsAs(ctx, &args, false)...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
+		}
+	}
+
+	command := cp.Command.ValueString()
+	tflog.Debug(ctx, "Running credential process", map[string]any{
+		"command": command,
+	})
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		detail := fmt.Sprintf("credential process %q failed: %v", command, err)
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			detail += ": " + message
+		}
+		diags.AddError("Credential Process Error", detail)
+		return "", time.Time{}, diags
+	}
+
+	output := stdout.String()
+	if !gjson.Valid(output) {
+		diags.AddError("Credential Process Output Error", fmt.Sprintf("credential process %q did not write a JSON object to stdout", command))
+		return "", time.Time{}, diags
+	}
+
+	var token string
+	for _, tokenPath := range credentialProcessTokenPaths {
+		if result := gjson.Get(output, tokenPath); result.Exists() && result.String() != "" {
+			token = result.String()
+			break
+		}
+	}
+	if token == "" {
+		diags.AddError(
+			"Credential Process Output Error",
+			fmt.Sprintf("credential process %q output did not contain a token in any of: %s", command, strings.Join(credentialProcessTokenPaths, ", ")),
+		)
+		return "", time.Time{}, diags
+	}
+
+	var expiry time.Time
+	for _, expiryPath := range credentialProcessExpiryPaths {
+		if result := gjson.Get(output, expiryPath.path); result.Exists() {
+			var err error
+			if expiryPath.relative {
+				expiry, err = parseTokenExpiry(result.String(), time.Now())
+			} else {
+				expiry, err = parseAbsoluteTokenExpiry(result.String())
+			}
+			if err != nil {
+				diags.AddError("Credential Process Token Expiry Error", err.Error())
+				return "", time.Time{}, diags
+			}
+			break
+		}
+	}
+
+	tflog.Debug(ctx, "Credential process returned a token", map[string]any{
+		"expiry": expiry,
+	})
+	return token, expiry, diags
+}
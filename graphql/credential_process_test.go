@@ -0,0 +1,142 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCredentialProcessHelper is not a real test. It is executed as the
+// credential process by the tests below and prints its last argument.
+func TestCredentialProcessHelper(t *testing.T) {
+	if os.Getenv("GRAPHQL_WANT_CREDENTIAL_PROCESS") != "1" {
+		return
+	}
+	output := os.Args[len(os.Args)-1]
+	if output == "fail" {
+		fmt.Fprint(os.Stderr, "login required")
+		os.Exit(1)
+	}
+	fmt.Fprint(os.Stdout, output)
+	os.Exit(0)
+}
+
+// newHelperCredentialProcess returns a credential_process block that runs the
+// test binary and prints output.
+func newHelperCredentialProcess(t *testing.T, output string) *CredentialProcessModel {
+	t.Helper()
+	t.Setenv("GRAPHQL_WANT_CREDENTIAL_PROCESS", "1")
+
+	return &CredentialProcessModel{
+		Command: types.StringValue(os.Args[0]),
+		Args: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue("-test.run=TestCredentialProcessHelper"),
+			types.StringValue("--"),
+			types.StringValue(output),
+		}),
+	}
+}
+
+func TestRunCredentialProcess(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		expectedToken  string
+		expectedExpiry time.Time
+		// expectedExpiresIn is checked instead of expectedExpiry for
+		// expiries relative to now
+		expectedExpiresIn time.Duration
+		expectError       bool
+	}{
+		{
+			name:           "token with RFC 3339 expiry",
+			output:         `{"token":"abc123","expires_at":"2030-01-01T00:00:00Z"}`,
+			expectedToken:  "abc123",
+			expectedExpiry: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "token with Unix timestamp expiry",
+			output:         `{"token":"abc123","expires_at":1893456000}`,
+			expectedToken:  "abc123",
+			expectedExpiry: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "token with expiration timestamp",
+			output:         `{"token":"abc123","expiration":1893456000}`,
+			expectedToken:  "abc123",
+			expectedExpiry: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "AWS-style Expiration",
+			output:         `{"token":"abc123","Expiration":"2030-01-01T00:00:00Z"}`,
+			expectedToken:  "abc123",
+			expectedExpiry: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:              "token with expires_in",
+			output:            `{"access_token":"abc123","expires_in":3600}`,
+			expectedToken:     "abc123",
+			expectedExpiresIn: time.Hour,
+		},
+		{
+			name:          "access token without expiry",
+			output:        `{"access_token":"abc123"}`,
+			expectedToken: "abc123",
+		},
+		{
+			name:           "kubectl ExecCredential",
+			output:         `{"kind":"ExecCredential","status":{"token":"k8s-token","expirationTimestamp":"2030-01-01T00:00:00Z"}}`,
+			expectedToken:  "k8s-token",
+			expectedExpiry: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "missing token",
+			output:      `{"expires_in":3600}`,
+			expectError: true,
+		},
+		{
+			name:        "not JSON",
+			output:      `abc123`,
+			expectError: true,
+		},
+		{
+			name:        "command fails",
+			output:      "fail",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &GraphqlProvider{}
+			token, expiry, diags := p.runCredentialProcess(context.Background(), newHelperCredentialProcess(t, tt.output))
+
+			if tt.expectError {
+				require.True(t, diags.HasError())
+				return
+			}
+			require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+			assert.Equal(t, tt.expectedToken, token)
+			if tt.expectedExpiresIn > 0 {
+				assert.WithinDuration(t, time.Now().Add(tt.expectedExpiresIn), expiry, time.Minute)
+				return
+			}
+			assert.True(t, tt.expectedExpiry.Equal(expiry), "expected %v, got %v", tt.expectedExpiry, expiry)
+		})
+	}
+}
+
+func TestRunCredentialProcess_ReportsStderr(t *testing.T) {
+	p := &GraphqlProvider{}
+	_, _, diags := p.runCredentialProcess(context.Background(), newHelperCredentialProcess(t, "fail"))
+
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), "login required")
+}
@@ -0,0 +1,364 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/kalenarndt/terraform-provider-graphql/internal/utils"
+	"github.com/tidwall/gjson"
+)
+
+// graphqlTransportWSProtocol is the WebSocket subprotocol of the graphql-ws
+// library, also supported by Apollo Server, gqlgen and Hasura.
+const graphqlTransportWSProtocol = "graphql-transport-ws"
+
+// defaultSubscriptionWaitTimeout is used when timeout is not set.
+const defaultSubscriptionWaitTimeout = 5 * time.Minute
+
+// GraphqlSubscriptionWaitDataSource waits for a subscription event
+type GraphqlSubscriptionWaitDataSource struct {
+	config *graphqlProviderConfig
+}
+
+// GraphqlSubscriptionWaitDataSourceModel describes the data source data model
+type GraphqlSubscriptionWaitDataSourceModel struct {
+	Query          types.String  `tfsdk:"query"`
+	QueryVariables types.Dynamic `tfsdk:"query_variables"`
+	ConditionPath  types.String  `tfsdk:"condition_path"`
+	ConditionValue types.String  `tfsdk:"condition_value"`
+	InitialQuery   types.String  `tfsdk:"initial_query"`
+	InitialPath    types.String  `tfsdk:"initial_condition_path"`
+	Timeout        types.String  `tfsdk:"timeout"`
+	URL            types.String  `tfsdk:"url"`
+	Event          types.String  `tfsdk:"event"`
+	ID             types.String  `tfsdk:"id"`
+}
+
+// NewGraphqlSubscriptionWaitDataSource creates a new GraphQL subscription wait data source
+func NewGraphqlSubscriptionWaitDataSource() datasource.DataSource {
+	return &GraphqlSubscriptionWaitDataSource{}
+}
+
+// Metadata returns the data source type name.
+func (d *GraphqlSubscriptionWaitDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subscription_wait"
+}
+
+// Schema defines the schema for the data source.
+func (d *GraphqlSubscriptionWaitDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = datasourceschema.Schema{
+		Description: "Waits until a GraphQL subscription pushes an event matching a condition, e.g. a deployment reaching READY. The subscription is opened over a WebSocket using the graphql-transport-ws protocol, with the provider's credentials and headers sent on the handshake.",
+		Attributes: map[string]datasourceschema.Attribute{
+			"query": datasourceschema.StringAttribute{
+				Required:    true,
+				Description: "The GraphQL subscription to execute.",
+			},
+			"query_variables": datasourceschema.DynamicAttribute{
+				Optional:    true,
+				Description: "Variables for the GraphQL subscription. Can be any valid JSON value (object, array, string, number, boolean, null).",
+			},
+			"condition_path": datasourceschema.StringAttribute{
+				Required:    true,
+				Description: "Path evaluated against each event's execution result, using GJSON syntax (e.g., `data.deploymentStatusChanged.status`). Without `condition_value`, the wait completes on the first event where the path exists and is not null or false.",
+			},
+			"condition_value": datasourceschema.StringAttribute{
+				Optional:    true,
+				Description: "Value the result of `condition_path` must equal, as a string (e.g., `READY`).",
+			},
+			"initial_query": datasourceschema.StringAttribute{
+				Optional:    true,
+				Description: "Query run with `query_variables` once the subscription is open. If its result already satisfies the condition, the wait completes without an event. Set this for subscriptions that only push changes, so reads after the condition is met do not wait for the full timeout.",
+			},
+			"initial_condition_path": datasourceschema.StringAttribute{
+				Optional:    true,
+				Description: "Path evaluated against the result of `initial_query`, compared with `condition_value` like `condition_path`. Default: `condition_path`.",
+			},
+			"timeout": datasourceschema.StringAttribute{
+				Optional:    true,
+				Description: "How long to wait for a matching event (e.g., '10m'). Default: 5m.",
+			},
+			"url": datasourceschema.StringAttribute{
+				Optional:    true,
+				Description: "WebSocket endpoint, as a ws://, wss://, http:// or https:// URL. Default: the provider's `url`.",
+			},
+			"event": datasourceschema.StringAttribute{
+				Computed:    true,
+				Description: "The execution result of the event, or of `initial_query`, that matched the condition, as JSON.",
+			},
+			"id": datasourceschema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the data source result.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *GraphqlSubscriptionWaitDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*graphqlProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *graphqlProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = config
+}
+
+// Read waits for an event matching the condition.
+func (d *GraphqlSubscriptionWaitDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	tflog.Debug(ctx, "Preparing to wait for GraphQL subscription event")
+
+	var data GraphqlSubscriptionWaitDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var variables map[string]interface{}
+	if !data.QueryVariables.IsNull() && !data.QueryVariables.IsUnknown() {
+		variablesJSON, diags := utils.DynamicToJSONString(ctx, data.QueryVariables)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if err := json.Unmarshal([]byte(variablesJSON), &variables); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("query_variables"), "Variable Parsing Error", fmt.Sprintf("failed to unmarshal variables from JSON string: %v", err))
+			return
+		}
+	}
+
+	timeout := defaultSubscriptionWaitTimeout
+	if isSet(data.Timeout) {
+		parsed, err := time.ParseDuration(data.Timeout.ValueString())
+		if err != nil || parsed <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Invalid Timeout", fmt.Sprintf("`timeout` must be a positive duration (e.g., '10m'), got %q.", data.Timeout.ValueString()))
+			return
+		}
+		timeout = parsed
+	}
+
+	endpoint := d.config.GQLServerUrl
+	if isSet(data.URL) {
+		endpoint = data.URL.ValueString()
+	}
+
+	condition := subscriptionCondition{path: data.ConditionPath.ValueString()}
+	if !data.ConditionValue.IsNull() && !data.ConditionValue.IsUnknown() {
+		condition.value = data.ConditionValue.ValueString()
+		condition.hasValue = true
+	}
+
+	var initial *subscriptionInitialCheck
+	if isSet(data.InitialQuery) {
+		initial = &subscriptionInitialCheck{query: data.InitialQuery.ValueString(), condition: condition}
+		if isSet(data.InitialPath) {
+			initial.condition.path = data.InitialPath.ValueString()
+		}
+	}
+
+	ctx = d.config.logContext(ctx, variables)
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	event, diags := waitForSubscriptionEvent(waitCtx, endpoint, data.Query.ValueString(), variables, condition, initial, d.config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Event = types.StringValue(string(event))
+	data.ID = types.StringValue(fmt.Sprintf("%d", hash(event)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Debug(ctx, "Finished waiting for GraphQL subscription event", map[string]any{"success": true})
+}
+
+// subscriptionCondition decides whether an event completes the wait.
+type subscriptionCondition struct {
+	path     string
+	value    string
+	hasValue bool
+}
+
+// matches reports whether an execution result satisfies the condition.
+func (c subscriptionCondition) matches(result []byte) bool {
+	value := gjson.GetBytes(result, c.path)
+	if c.hasValue {
+		return value.Exists() && value.String() == c.value
+	}
+	return value.Exists() && value.Type != gjson.Null && value.Type != gjson.False
+}
+
+// subscriptionInitialCheck is a query that completes the wait when its result
+// already satisfies the condition.
+type subscriptionInitialCheck struct {
+	query     string
+	condition subscriptionCondition
+}
+
+// run executes the query and reports whether its result matches.
+func (c *subscriptionInitialCheck) run(ctx context.Context, variables map[string]interface{}, config *graphqlProviderConfig) ([]byte, bool, diag.Diagnostics) {
+	queryResponse, body, diags := executeGraphQLRequestFramework(ctx, c.query, variables, config)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+	if len(queryResponse.Errors) > 0 {
+		for _, gqlErr := range queryResponse.Errors {
+			diags.AddError("GraphQL Server Error", gqlErr.Message)
+		}
+		return nil, false, diags
+	}
+	return body, c.condition.matches(body), diags
+}
+
+// graphqlTransportWSMessage is a message of the graphql-transport-ws protocol.
+type graphqlTransportWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// waitForSubscriptionEvent subscribes over graphql-transport-ws and returns
+// the execution result of the first event matching the condition, or of the
+// initial check if it already matches. The check runs after subscribing so no
+// event is missed in between. It fails when the subscription reports errors,
+// completes, or the context is done.
+func waitForSubscriptionEvent(ctx context.Context, endpoint, query string, variables map[string]interface{}, condition subscriptionCondition, initial *subscriptionInitialCheck, config *graphqlProviderConfig) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tflog.Debug(ctx, "Opening GraphQL subscription", map[string]any{
+		"url":       endpoint,
+		"query":     query,
+		"variables": config.redactVariables(variables),
+	})
+
+	conn, dialDiags := dialWebSocket(ctx, endpoint, graphqlTransportWSProtocol, config)
+	diags.Append(dialDiags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	defer conn.Close()
+
+	// Unblock reads when the wait times out
+	stop := context.AfterFunc(ctx, func() { conn.conn.Close() })
+	defer stop()
+
+	connectionDiags := func(err error) diag.Diagnostics {
+		var diags diag.Diagnostics
+		if ctx.Err() != nil {
+			diags.AddError("Subscription Wait Timeout", fmt.Sprintf("no event matching %s was received: %v", condition.path, ctx.Err()))
+		} else {
+			diags.AddError("WebSocket Error", fmt.Sprintf("subscription connection failed: %v", err))
+		}
+		return diags
+	}
+
+	send := func(message graphqlTransportWSMessage) error {
+		encoded, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		return conn.WriteText(encoded)
+	}
+
+	if err := send(graphqlTransportWSMessage{Type: "connection_init"}); err != nil {
+		return nil, connectionDiags(err)
+	}
+
+	subscribed := false
+	for {
+		raw, err := conn.ReadMessage()
+		if err != nil {
+			return nil, connectionDiags(err)
+		}
+		var message graphqlTransportWSMessage
+		if err := json.Unmarshal(raw, &message); err != nil {
+			diags.AddError("Subscription Protocol Error", fmt.Sprintf("failed to parse message: %v", err))
+			return nil, diags
+		}
+
+		switch message.Type {
+		case "connection_ack":
+			if subscribed {
+				continue
+			}
+			payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+			if err != nil {
+				diags.AddError("Request Encoding Error", fmt.Sprintf("failed to encode subscription: %v", err))
+				return nil, diags
+			}
+			if err := send(graphqlTransportWSMessage{ID: "1", Type: "subscribe", Payload: payload}); err != nil {
+				return nil, connectionDiags(err)
+			}
+			subscribed = true
+
+			if initial != nil {
+				result, matched, checkDiags := initial.run(ctx, variables, config)
+				diags.Append(checkDiags...)
+				if diags.HasError() {
+					return nil, diags
+				}
+				if matched {
+					tflog.Debug(ctx, "Initial query already satisfies the subscription condition")
+					_ = send(graphqlTransportWSMessage{ID: "1", Type: "complete"})
+					return result, diags
+				}
+			}
+		case "ping":
+			if err := send(graphqlTransportWSMessage{Type: "pong"}); err != nil {
+				return nil, connectionDiags(err)
+			}
+		case "pong":
+		case "next":
+			tflog.Debug(ctx, "Received GraphQL subscription event", map[string]any{
+				"eventLength": len(message.Payload),
+			})
+			var result GqlQueryResponse
+			if err := json.Unmarshal(message.Payload, &result); err != nil {
+				diags.AddError("Response Parsing Error", fmt.Sprintf("failed to parse subscription event: %v: %s", err, summarizeBody("", message.Payload)))
+				return nil, diags
+			}
+			if len(result.Errors) > 0 {
+				for _, gqlErr := range result.Errors {
+					diags.AddError("GraphQL Server Error", gqlErr.Message)
+				}
+				return nil, diags
+			}
+			if condition.matches(message.Payload) {
+				_ = send(graphqlTransportWSMessage{ID: "1", Type: "complete"})
+				return message.Payload, diags
+			}
+		case "error":
+			var gqlErrors []GqlError
+			if err := json.Unmarshal(message.Payload, &gqlErrors); err != nil || len(gqlErrors) == 0 {
+				diags.AddError("GraphQL Server Error", fmt.Sprintf("subscription failed: %s", summarizeBody("", message.Payload)))
+				return nil, diags
+			}
+			for _, gqlErr := range gqlErrors {
+				diags.AddError("GraphQL Server Error", gqlErr.Message)
+			}
+			return nil, diags
+		case "complete":
+			diags.AddError("Subscription Completed", fmt.Sprintf("the subscription completed before an event matching %s was received", condition.path))
+			return nil, diags
+		default:
+			diags.AddError("Subscription Protocol Error", fmt.Sprintf("unexpected %q message", message.Type))
+			return nil, diags
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSubscriptionServer accepts graphql-transport-ws connections and answers
+// a subscribe message with the given messages, then keeps the connection
+// open until the client closes it. Queries report the deployment as READY.
+func newSubscriptionServer(t *testing.T, messages ...string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"data":{"deployment":{"status":"READY"}}}`))
+			return
+		}
+		assert.Equal(t, "websocket", r.Header.Get("Upgrade"))
+		assert.Equal(t, graphqlTransportWSProtocol, r.Header.Get("Sec-WebSocket-Protocol"))
+
+		hijacked, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer hijacked.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + webSocketAcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n" +
+			"Sec-WebSocket-Protocol: " + graphqlTransportWSProtocol + "\r\n\r\n")
+		require.NoError(t, rw.Flush())
+
+		conn := newWebSocketConn(hijacked, false)
+		conn.r = rw.Reader
+		for {
+			raw, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var message graphqlTransportWSMessage
+			require.NoError(t, json.Unmarshal(raw, &message))
+			switch message.Type {
+			case "connection_init":
+				_ = conn.WriteText([]byte(`{"type":"ping"}`))
+				_ = conn.WriteText([]byte(`{"type":"connection_ack"}`))
+			case "subscribe":
+				assert.Contains(t, string(message.Payload), `"variables":{"id":"d1"}`)
+				for _, m := range messages {
+					_ = conn.WriteText([]byte(m))
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWaitForSubscriptionEvent(t *testing.T) {
+	building := `{"id":"1","type":"next","payload":{"data":{"deploymentStatusChanged":{"status":"BUILDING"}}}}`
+	ready := `{"id":"1","type":"next","payload":{"data":{"deploymentStatusChanged":{"status":"READY"}}}}`
+
+	tests := []struct {
+		name        string
+		messages    []string
+		condition   subscriptionCondition
+		initial     *subscriptionInitialCheck
+		expected    string
+		expectError string
+	}{
+		{
+			name:      "condition value",
+			messages:  []string{building, ready},
+			condition: subscriptionCondition{path: "data.deploymentStatusChanged.status", value: "READY", hasValue: true},
+			expected:  `{"data":{"deploymentStatusChanged":{"status":"READY"}}}`,
+		},
+		{
+			name:      "path exists",
+			messages:  []string{`{"id":"1","type":"next","payload":{"data":{"deploymentStatusChanged":null}}}`, building},
+			condition: subscriptionCondition{path: "data.deploymentStatusChanged"},
+			expected:  `{"data":{"deploymentStatusChanged":{"status":"BUILDING"}}}`,
+		},
+		{
+			name:      "initial query already matches",
+			condition: subscriptionCondition{path: "data.deploymentStatusChanged.status", value: "READY", hasValue: true},
+			initial: &subscriptionInitialCheck{
+				query:     "query($id: ID!) { deployment(id: $id) { status } }",
+				condition: subscriptionCondition{path: "data.deployment.status", value: "READY", hasValue: true},
+			},
+			expected: `{"data":{"deployment":{"status":"READY"}}}`,
+		},
+		{
+			name:      "initial query does not match",
+			messages:  []string{building, ready},
+			condition: subscriptionCondition{path: "data.deploymentStatusChanged.status", value: "READY", hasValue: true},
+			initial: &subscriptionInitialCheck{
+				query:     "query($id: ID!) { deployment(id: $id) { status } }",
+				condition: subscriptionCondition{path: "data.deployment.status", value: "DELETED", hasValue: true},
+			},
+			expected: `{"data":{"deploymentStatusChanged":{"status":"READY"}}}`,
+		},
+		{
+			name:        "subscription error",
+			messages:    []string{`{"id":"1","type":"error","payload":[{"message":"deployment not found"}]}`},
+			condition:   subscriptionCondition{path: "data.deploymentStatusChanged"},
+			expectError: "deployment not found",
+		},
+		{
+			name:        "errors in an event",
+			messages:    []string{`{"id":"1","type":"next","payload":{"errors":[{"message":"forbidden"}]}}`},
+			condition:   subscriptionCondition{path: "data.deploymentStatusChanged"},
+			expectError: "forbidden",
+		},
+		{
+			name:        "completed",
+			messages:    []string{building, `{"id":"1","type":"complete"}`},
+			condition:   subscriptionCondition{path: "data.deploymentStatusChanged.status", value: "READY", hasValue: true},
+			expectError: "completed before",
+		},
+		{
+			name:        "timeout",
+			messages:    []string{building},
+			condition:   subscriptionCondition{path: "data.deploymentStatusChanged.status", value: "READY", hasValue: true},
+			expectError: "no event matching",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSubscriptionServer(t, tt.messages...)
+			config := &graphqlProviderConfig{
+				GQLServerUrl:   server.URL,
+				RequestHeaders: map[string]interface{}{"Authorization": "Bearer secret"},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			event, diags := waitForSubscriptionEvent(ctx, strings.Replace(server.URL, "http://", "ws://", 1), "subscription($id: ID!) { deploymentStatusChanged(id: $id) { status } }", map[string]interface{}{"id": "d1"}, tt.condition, tt.initial, config)
+			if tt.expectError != "" {
+				require.True(t, diags.HasError())
+				assert.Contains(t, diags.Errors()[0].Detail(), tt.expectError)
+				return
+			}
+			require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+			assert.JSONEq(t, tt.expected, string(event))
+		})
+	}
+}
+
+func TestWaitForSubscriptionEvent_Unauthorized(t *testing.T) {
+	server := newSubscriptionServer(t)
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+	}
+
+	_, diags := waitForSubscriptionEvent(context.Background(), server.URL, "subscription { ping }", nil, subscriptionCondition{path: "data.ping"}, nil, config)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags.Errors()[0].Detail(), "HTTP 401")
+}
@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cachedFile reads a file that may be rotated by an external process, such
+// as a Vault agent sidecar. The contents are cached and only read again when
+// the file's modification time or size changes.
+type cachedFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	content []byte
+}
+
+// newCachedFile creates a cached reader for the file at path.
+func newCachedFile(path string) *cachedFile {
+	return &cachedFile{path: path}
+}
+
+// Read returns the current contents of the file.
+func (f *cachedFile) Read() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.content != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.content, nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	f.content = content
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return content, nil
+}
+
+// cached returns the contents from the last read, without reading the file.
+func (f *cachedFile) cached() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.content
+}
+
+// ReadToken returns the file contents as a bearer token, without surrounding whitespace.
+func (f *cachedFile) ReadToken() (string, error) {
+	content, err := f.Read()
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+	return token, nil
+}
+
+// ReadHeaders parses the file contents as a JSON object of header names to string values.
+func (f *cachedFile) ReadHeaders() (map[string]string, error) {
+	content, err := f.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal(content, &headers); err != nil {
+		return nil, fmt.Errorf("headers file %s must contain a JSON object of string values: %w", f.path, err)
+	}
+	return headers, nil
+}
@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFileWithModTime writes content to a file and sets its modification time.
+func writeFileWithModTime(t *testing.T, name, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(name, modTime, modTime))
+}
+
+func TestCachedFile_Read(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "token")
+	modTime := time.Now().Add(-time.Hour)
+	writeFileWithModTime(t, name, "token-1\n", modTime)
+
+	file := newCachedFile(name)
+	token, err := file.ReadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// Unchanged modification time and size are served from the cache
+	writeFileWithModTime(t, name, "token-2\n", modTime)
+	token, err = file.ReadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// A rotated file is read again
+	writeFileWithModTime(t, name, "token-2\n", modTime.Add(time.Minute))
+	token, err = file.ReadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	writeFileWithModTime(t, name, "  \n", modTime.Add(2*time.Minute))
+	_, err = file.ReadToken()
+	assert.Error(t, err)
+}
+
+func TestCachedFile_ReadHeaders(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "headers.json")
+
+	require.NoError(t, os.WriteFile(name, []byte(`{"X-Api-Key":"abc","X-Tenant":"acme"}`), 0o600))
+	headers, err := newCachedFile(name).ReadHeaders()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Api-Key": "abc", "X-Tenant": "acme"}, headers)
+
+	require.NoError(t, os.WriteFile(name, []byte(`{"X-Retries":3}`), 0o600))
+	_, err = newCachedFile(name).ReadHeaders()
+	assert.Error(t, err)
+
+	_, err = newCachedFile(filepath.Join(t.TempDir(), "missing.json")).ReadHeaders()
+	assert.Error(t, err)
+}
+
+func TestExecuteSingleGraphQLRequest_FileCredentials(t *testing.T) {
+	var authorization, tenant, static string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		tenant = r.Header.Get("X-Tenant")
+		static = r.Header.Get("X-Static")
+		_, _ = w.Write([]byte(`{"data":{"todo":{"id":"1"}}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	headersFile := filepath.Join(dir, "headers.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeFileWithModTime(t, tokenFile, "token-1", modTime)
+	writeFileWithModTime(t, headersFile, `{"X-Tenant":"from-file"}`, modTime)
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{"X-Tenant": "static", "X-Static": "value"},
+		TokenFile:      newCachedFile(tokenFile),
+		HeadersFile:    newCachedFile(headersFile),
+	}
+
+	_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { todo { id } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, "Bearer token-1", authorization)
+	assert.Equal(t, "from-file", tenant)
+	assert.Equal(t, "value", static)
+
+	writeFileWithModTime(t, tokenFile, "token-2", modTime.Add(time.Minute))
+
+	_, _, _, diags = executeSingleGraphQLRequest(context.Background(), "query { todo { id } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, "Bearer token-2", authorization)
+}
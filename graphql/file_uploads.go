@@ -0,0 +1,192 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fileUpload is a local file sent as an Upload scalar variable.
+type fileUpload struct {
+	// VariablePath is the dot-separated path of the variable, e.g.
+	// input.certificate or input.files.0
+	VariablePath string
+	FilePath     string
+}
+
+// fileUploadsKey is the context key for the files uploaded with a request.
+type fileUploadsKey struct{}
+
+// withFileUploads returns a context whose GraphQL requests upload the files.
+func withFileUploads(ctx context.Context, uploads []fileUpload) context.Context {
+	if len(uploads) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fileUploadsKey{}, uploads)
+}
+
+// fileUploadsFromContext returns the files to upload with requests made with
+// the context.
+func fileUploadsFromContext(ctx context.Context) []fileUpload {
+	uploads, _ := ctx.Value(fileUploadsKey{}).([]fileUpload)
+	return uploads
+}
+
+// expandFileUploads converts the file_uploads attribute to uploads, sorted by
+// variable path.
+func expandFileUploads(ctx context.Context, value types.Map) ([]fileUpload, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if value.IsNull() || value.IsUnknown() {
+		return nil, diags
+	}
+
+	elements := make(map[string]string)
+	diags.Append(value.ElementsAs(ctx, &elements, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	uploads := make([]fileUpload, 0, len(elements))
+	for variablePath, filePath := range elements {
+		if variablePath == "" || filePath == "" {
+			diags.AddAttributeError(path.Root("file_uploads"), "Invalid File Upload", "`file_uploads` keys and values must not be empty.")
+			return nil, diags
+		}
+		uploads = append(uploads, fileUpload{VariablePath: variablePath, FilePath: filePath})
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].VariablePath < uploads[j].VariablePath })
+	return uploads, diags
+}
+
+// fileUploadsHash returns a hash of the uploaded files' variable paths and
+// contents, so editing a file changes the plan.
+func fileUploadsHash(uploads []fileUpload) (string, error) {
+	digest := sha256.New()
+	for _, upload := range uploads {
+		file, err := os.Open(upload.FilePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to open file for %s: %w", upload.VariablePath, err)
+		}
+		contentDigest := sha256.New()
+		_, err = io.Copy(contentDigest, file)
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", upload.FilePath, err)
+		}
+		fmt.Fprintf(digest, "%s\x00%x\n", upload.VariablePath, contentDigest.Sum(nil))
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// buildMultipartBody encodes a request with file uploads following the
+// GraphQL multipart request specification: an operations part with the file
+// variables set to null, a map part assigning each file to its variable, and
+// one part per file.
+func buildMultipartBody(request graphQLRequest, uploads []fileUpload) ([]byte, string, error) {
+	// Copy the variables, as the file variables are replaced by null
+	variables := map[string]interface{}{}
+	if len(request.Variables) > 0 {
+		encoded, err := json.Marshal(request.Variables)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode variables: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &variables); err != nil {
+			return nil, "", fmt.Errorf("failed to copy variables: %w", err)
+		}
+	}
+
+	fileMap := make(map[string][]string, len(uploads))
+	for i, upload := range uploads {
+		if err := setVariableNull(variables, upload.VariablePath); err != nil {
+			return nil, "", err
+		}
+		fileMap[strconv.Itoa(i)] = []string{"variables." + upload.VariablePath}
+	}
+	request.Variables = variables
+
+	operations, err := json.Marshal(request.body())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode operations: %w", err)
+	}
+	mapping, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode file map: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("operations", string(operations)); err != nil {
+		return nil, "", err
+	}
+	if err := writer.WriteField("map", string(mapping)); err != nil {
+		return nil, "", err
+	}
+	for i, upload := range uploads {
+		contents, err := os.ReadFile(upload.FilePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file for %s: %w", upload.VariablePath, err)
+		}
+		part, err := writer.CreateFormFile(strconv.Itoa(i), filepath.Base(upload.FilePath))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(contents); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// setVariableNull sets the variable at a dot-separated path to null, creating
+// missing objects along the way. Numeric segments index into lists.
+func setVariableNull(variables map[string]interface{}, variablePath string) error {
+	segments := strings.Split(variablePath, ".")
+	var current interface{} = variables
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if last {
+				node[segment] = nil
+				return nil
+			}
+			next, ok := node[segment]
+			if !ok || next == nil {
+				next = map[string]interface{}{}
+				node[segment] = next
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return fmt.Errorf("invalid file upload path %q: %q is not an index of the list at that position", variablePath, segment)
+			}
+			if last {
+				node[index] = nil
+				return nil
+			}
+			current = node[index]
+		default:
+			return fmt.Errorf("invalid file upload path %q: %q is not an object or list", variablePath, strings.Join(segments[:i], "."))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVariableNull(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		expected  string
+		expectErr bool
+	}{
+		{name: "nested object", path: "input.certificate", expected: `{"input":{"certificate":null,"files":["a","b"],"name":"web"}}`},
+		{name: "missing object", path: "icon.file", expected: `{"icon":{"file":null},"input":{"files":["a","b"],"name":"web"}}`},
+		{name: "list index", path: "input.files.1", expected: `{"input":{"files":["a",null],"name":"web"}}`},
+		{name: "index out of range", path: "input.files.2", expectErr: true},
+		{name: "through a scalar", path: "input.name.value", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variables := map[string]interface{}{
+				"input": map[string]interface{}{"name": "web", "files": []interface{}{"a", "b"}},
+			}
+			err := setVariableNull(variables, tt.path)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			encoded, err := json.Marshal(variables)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(encoded))
+		})
+	}
+}
+
+func TestFileUploadsHash(t *testing.T) {
+	dir := t.TempDir()
+	certificate := filepath.Join(dir, "cert.pem")
+	require.NoError(t, os.WriteFile(certificate, []byte("v1"), 0o600))
+
+	uploads, diags := expandFileUploads(context.Background(), types.MapValueMust(types.StringType, map[string]attr.Value{
+		"input.certificate": types.StringValue(certificate),
+	}))
+	require.False(t, diags.HasError())
+
+	first, err := fileUploadsHash(uploads)
+	require.NoError(t, err)
+	unchanged, err := fileUploadsHash(uploads)
+	require.NoError(t, err)
+	assert.Equal(t, first, unchanged)
+
+	require.NoError(t, os.WriteFile(certificate, []byte("v2"), 0o600))
+	edited, err := fileUploadsHash(uploads)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, edited, "editing the file changes the hash")
+
+	_, err = fileUploadsHash([]fileUpload{{VariablePath: "input.icon", FilePath: filepath.Join(dir, "missing.png")}})
+	assert.Error(t, err)
+}
+
+func TestSendGraphQLRequest_FileUploads(t *testing.T) {
+	dir := t.TempDir()
+	certificate := filepath.Join(dir, "cert.pem")
+	require.NoError(t, os.WriteFile(certificate, []byte("-----BEGIN CERTIFICATE-----"), 0o600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		var operations map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(r.FormValue("operations")), &operations))
+		assert.Equal(t, "mutation($input: CertInput!) { upload(input: $input) { id } }", operations["query"])
+		assert.Equal(t, map[string]interface{}{"input": map[string]interface{}{"name": "web", "certificate": nil}}, operations["variables"])
+		assert.JSONEq(t, `{"0":["variables.input.certificate"]}`, r.FormValue("map"))
+
+		file, header, err := r.FormFile("0")
+		require.NoError(t, err)
+		defer file.Close()
+		contents, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "cert.pem", header.Filename)
+		assert.Equal(t, "-----BEGIN CERTIFICATE-----", string(contents))
+
+		_, _ = w.Write([]byte(`{"data":{"upload":{"id":"1"}}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+	}
+	variables := map[string]interface{}{"input": map[string]interface{}{"name": "web"}}
+	ctx := withFileUploads(context.Background(), []fileUpload{{VariablePath: "input.certificate", FilePath: certificate}})
+
+	_, _, _, diags := executeSingleGraphQLRequest(ctx, "mutation($input: CertInput!) { upload(input: $input) { id } }", variables, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, map[string]interface{}{"input": map[string]interface{}{"name": "web"}}, variables, "the caller's variables are not modified")
+}
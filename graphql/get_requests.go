@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxETagCacheEntries bounds the number of responses kept for conditional
+// requests, so a long-running plan with many distinct queries does not grow
+// the cache without limit.
+const maxETagCacheEntries = 1000
+
+// operationNamePattern matches the name of an operation at the start of a
+// document, after any whitespace and comments.
+var operationNamePattern = regexp.MustCompile(`^(?:\s|,|#[^\n]*)*(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+
+// validateQueryMethod checks the query_method attribute and returns the HTTP
+// method to send queries with.
+func validateQueryMethod(method string) (string, error) {
+	switch strings.ToUpper(method) {
+	case "", http.MethodPost:
+		return http.MethodPost, nil
+	case http.MethodGet:
+		return http.MethodGet, nil
+	default:
+		return "", fmt.Errorf("`query_method` must be either %q or %q, got %q", http.MethodPost, http.MethodGet, method)
+	}
+}
+
+// requestMethod returns the HTTP method to send an operation with. Mutations
+// are always sent with POST, as GET requests must not have side effects.
+func (c *graphqlProviderConfig) requestMethod(query string) string {
+	if c.QueryMethod == http.MethodGet && !isMutationQuery(query) {
+		return http.MethodGet
+	}
+	return http.MethodPost
+}
+
+// operationName returns the name of the operation defined at the start of the
+// document, or an empty string for anonymous operations.
+func operationName(query string) string {
+	match := operationNamePattern.FindStringSubmatch(query)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// buildGetURL encodes a GraphQL request as URL parameters, as described by
+// the GraphQL over HTTP specification.
+func buildGetURL(serverURL string, request graphQLRequest) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	params := u.Query()
+	if !request.OmitQuery {
+		params.Set("query", request.Query)
+	}
+	if name := operationName(request.Query); name != "" {
+		params.Set("operationName", name)
+	}
+	if len(request.Variables) > 0 {
+		variables, err := json.Marshal(request.Variables)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode variables: %w", err)
+		}
+		params.Set("variables", string(variables))
+	}
+	if len(request.Extensions) > 0 {
+		extensions, err := json.Marshal(request.Extensions)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode extensions: %w", err)
+		}
+		params.Set("extensions", string(extensions))
+	}
+
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// etagCache keeps the last response to each GET request along with its ETag,
+// so it can be revalidated with If-None-Match instead of downloaded again.
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagCacheEntry
+}
+
+// etagCacheEntry is a cached response body with the ETag and content type it
+// was served with. A 304 response may not repeat the content type, which is
+// needed to parse e.g. multipart bodies.
+type etagCacheEntry struct {
+	etag        string
+	contentType string
+	body        []byte
+}
+
+// newETagCache returns an empty response cache.
+func newETagCache() *etagCache {
+	return &etagCache{entries: make(map[string]etagCacheEntry)}
+}
+
+// Get returns the cached response for a request URL.
+func (c *etagCache) Get(requestURL string) (etagCacheEntry, bool) {
+	if c == nil {
+		return etagCacheEntry{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[requestURL]
+	return entry, ok
+}
+
+// Store caches a response body for a request URL if it has an ETag.
+func (c *etagCache) Store(requestURL, etag, contentType string, body []byte) {
+	if c == nil || etag == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[requestURL]; !ok && len(c.entries) >= maxETagCacheEntries {
+		// Evict an arbitrary entry to make room
+		for key := range c.entries {
+			delete(c.entries, key)
+			break
+		}
+	}
+	c.entries[requestURL] = etagCacheEntry{etag: etag, contentType: contentType, body: body}
+}
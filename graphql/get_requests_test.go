@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "query GetUser($id: ID!) { user(id: $id) { id } }", expected: "GetUser"},
+		{query: "\n  # Fetch the user\n  query GetUser { user { id } }", expected: "GetUser"},
+		{query: "mutation CreateUser { createUser { id } }", expected: "CreateUser"},
+		{query: "{ query id }", expected: ""},
+		{query: "query { user { id } }", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, operationName(tt.query))
+		})
+	}
+}
+
+func TestValidateQueryMethod(t *testing.T) {
+	method, err := validateQueryMethod("get")
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, method)
+
+	method, err = validateQueryMethod("")
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, method)
+
+	_, err = validateQueryMethod("PUT")
+	assert.Error(t, err)
+}
+
+func TestQueryMethodGet(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.Method == http.MethodGet && r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"data":{"user":{"id":"1"}}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL + "/graphql?tenant=a",
+		RequestHeaders: map[string]interface{}{},
+		QueryMethod:    http.MethodGet,
+		ETags:          newETagCache(),
+	}
+	query := "query GetUser($id: ID!) { user(id: $id) { id } }"
+	variables := map[string]interface{}{"id": "1"}
+
+	for i := 0; i < 2; i++ {
+		resp, body, _, diags := executeSingleGraphQLRequest(context.Background(), query, variables, config)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		assert.Equal(t, map[string]interface{}{"user": map[string]interface{}{"id": "1"}}, resp.Data)
+		assert.JSONEq(t, `{"data":{"user":{"id":"1"}}}`, string(body))
+	}
+
+	require.Len(t, requests, 2)
+	for _, r := range requests {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "a", r.URL.Query().Get("tenant"))
+		assert.Equal(t, query, r.URL.Query().Get("query"))
+		assert.Equal(t, "GetUser", r.URL.Query().Get("operationName"))
+		var sent map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("variables")), &sent))
+		assert.Equal(t, variables, sent)
+	}
+	assert.Empty(t, requests[0].Header.Get("If-None-Match"))
+	assert.Equal(t, `"v1"`, requests[1].Header.Get("If-None-Match"), "the second request is revalidated with the cached ETag")
+
+	// Mutations are always POSTed
+	_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "mutation { touch }", nil, config)
+	require.False(t, diags.HasError())
+	require.Len(t, requests, 3)
+	assert.Equal(t, http.MethodPost, requests[2].Method)
+
+	// Login flows never put credentials in the URL
+	assert.Equal(t, http.MethodPost, config.withoutAuthentication().requestMethod(query))
+}
@@ -16,12 +16,14 @@ type GqlQuery struct {
 type GqlQueryResponse struct {
 	Data                  map[string]interface{}   `json:"data,omitempty"`
 	Errors                []GqlError               `json:"errors,omitempty"`
+	Extensions            map[string]interface{}   `json:"extensions,omitempty"`
 	PaginatedResponseData []map[string]interface{} `json:"paginatedResponseData,omitempty"`
 }
 
 // GqlError represents a GraphQL error message.
 type GqlError struct {
-	Message string `json:"message,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // ProcessErrors converts GraphQL errors to Terraform diagnostics.
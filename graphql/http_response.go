@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"fmt"
+	"html"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// graphqlResponseMediaType is the media type defined by the GraphQL over
+	// HTTP specification, which gives status codes a well-defined meaning.
+	graphqlResponseMediaType = "application/graphql-response+json"
+	// graphqlAcceptHeader prefers the GraphQL response media type while still
+	// accepting servers that only speak application/json, and incremental
+	// delivery of @defer and @stream results.
+	graphqlAcceptHeader = graphqlResponseMediaType + ", application/json;q=0.9, " + incrementalDeliveryMediaType + ";q=0.8"
+	// maxErrorBodyLength is the number of characters of an unstructured error
+	// response included in diagnostics.
+	maxErrorBodyLength = 512
+)
+
+var (
+	// htmlIgnoredElementsPattern matches elements whose content is not text.
+	htmlIgnoredElementsPattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	// htmlTitlePattern matches the title of an HTML page.
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	// htmlTagPattern matches any HTML tag or comment.
+	htmlTagPattern = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+)
+
+// isSuccessStatus reports whether the status code indicates that the server
+// executed the request.
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// describeErrorResponse returns a readable description of an error response:
+// the GraphQL error messages if it has any, otherwise the body, with HTML
+// pages reduced to their text and long bodies truncated.
+func describeErrorResponse(contentType string, body []byte, queryResponse *GqlQueryResponse) string {
+	if queryResponse != nil && len(queryResponse.Errors) > 0 {
+		messages := make([]string, 0, len(queryResponse.Errors))
+		for _, gqlErr := range queryResponse.Errors {
+			message := gqlErr.Message
+			if code, ok := gqlErr.Extensions["code"].(string); ok && code != "" {
+				message = fmt.Sprintf("%s (%s)", message, code)
+			}
+			messages = append(messages, message)
+		}
+		return strings.Join(messages, "; ")
+	}
+	return summarizeBody(contentType, body)
+}
+
+// summarizeBody reduces a response body to readable, bounded text for use in
+// diagnostics.
+func summarizeBody(contentType string, body []byte) string {
+	text := string(body)
+	if isHTML(contentType, text) {
+		text = htmlText(text)
+	} else {
+		text = strings.TrimSpace(text)
+	}
+	if text == "" {
+		return "(empty response body)"
+	}
+	return truncate(text, maxErrorBodyLength)
+}
+
+// isHTML reports whether a response is an HTML page, such as an error page
+// served by a proxy or load balancer.
+func isHTML(contentType, body string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+			return true
+		}
+	}
+	trimmed := strings.ToLower(strings.TrimSpace(body))
+	return strings.HasPrefix(trimmed, "<!doctype html") || strings.HasPrefix(trimmed, "<html")
+}
+
+// htmlText returns the title and visible text of an HTML page with
+// whitespace collapsed.
+func htmlText(page string) string {
+	var title string
+	if match := htmlTitlePattern.FindStringSubmatch(page); match != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(match[1])), " ")
+	}
+
+	text := htmlIgnoredElementsPattern.ReplaceAllString(page, " ")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+
+	switch {
+	case title == "":
+		return text
+	case text == "" || text == title:
+		return title
+	default:
+		return title + ": " + text
+	}
+}
+
+// truncate shortens text to at most limit characters, noting how much was cut.
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return fmt.Sprintf("%s... (truncated, %d bytes total)", string(runes[:limit]), len(text))
+}
@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "html error page",
+			contentType: "text/html; charset=utf-8",
+			body: `<html><head><title>502 Bad Gateway</title><style>body { color: red; }</style></head>
+<body><center><h1>502 Bad Gateway</h1></center><hr><center>nginx</center></body></html>`,
+			expected: "502 Bad Gateway: 502 Bad Gateway nginx",
+		},
+		{
+			name:     "html without content type",
+			body:     "<!DOCTYPE html><html><body><p>Access &amp; denied</p><script>track()</script></body></html>",
+			expected: "Access & denied",
+		},
+		{
+			name:        "plain text",
+			contentType: "text/plain",
+			body:        "  upstream connect error\n",
+			expected:    "upstream connect error",
+		},
+		{
+			name:     "empty body",
+			expected: "(empty response body)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, summarizeBody(tt.contentType, []byte(tt.body)))
+		})
+	}
+}
+
+func TestSummarizeBody_Truncates(t *testing.T) {
+	body := strings.Repeat("x", 2000)
+	summary := summarizeBody("text/plain", []byte(body))
+	assert.True(t, strings.HasPrefix(summary, strings.Repeat("x", maxErrorBodyLength)+"..."))
+	assert.Contains(t, summary, "2000 bytes total")
+}
+
+func TestSendGraphQLRequest_ErrorResponses(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		contentType    string
+		body           string
+		expectedDetail string
+		expectErrors   int
+	}{
+		{
+			name:           "graphql response with errors",
+			status:         http.StatusBadRequest,
+			contentType:    graphqlResponseMediaType,
+			body:           `{"errors":[{"message":"Cannot query field \"nme\" on type \"User\".","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`,
+			expectedDetail: `received HTTP 400: Cannot query field "nme" on type "User". (GRAPHQL_VALIDATION_FAILED)`,
+			expectErrors:   1,
+		},
+		{
+			name:           "html error page",
+			status:         http.StatusForbidden,
+			contentType:    "text/html",
+			body:           "<html><head><title>403 Forbidden</title></head><body><h1>403 Forbidden</h1></body></html>",
+			expectedDetail: "received HTTP 403: 403 Forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, graphqlAcceptHeader, r.Header.Get("Accept"))
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := &graphqlProviderConfig{
+				GQLServerUrl:   server.URL,
+				RequestHeaders: map[string]interface{}{},
+			}
+			resp, _, requestErr, diags := executeSingleGraphQLRequest(context.Background(), "query { user { nme } }", nil, config)
+			require.True(t, diags.HasError())
+			assert.Equal(t, "HTTP Error", diags[0].Summary())
+			assert.Equal(t, tt.expectedDetail, diags[0].Detail())
+			require.NotNil(t, requestErr)
+			assert.Equal(t, tt.status, requestErr.StatusCode)
+			assert.Len(t, requestErr.GraphQLErrors, tt.expectErrors)
+			if tt.expectErrors > 0 {
+				require.NotNil(t, resp)
+				assert.Len(t, resp.Errors, tt.expectErrors)
+			}
+		})
+	}
+}
+
+func TestSendGraphQLRequest_UnparseableSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><head><title>Sign in</title></head><body>Please sign in</body></html>"))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+	}
+	_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { ok }", nil, config)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Response Parsing Error", diags[0].Summary())
+	assert.Contains(t, diags[0].Detail(), "Sign in: Please sign in")
+}
@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Transport defaults, used when the http block or one of its attributes is not set.
+const (
+	defaultHTTPTimeout         = 30 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxIdleConnsPerHost = 10
+	defaultMaxRetries          = 5
+	defaultRetryBackoffBase    = time.Second
+	defaultRetryBackoffMax     = 30 * time.Second
+)
+
+// defaultHTTPClient is shared by configurations that were not built by
+// Configure, so they still reuse pooled connections.
+var defaultHTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+
+// HTTPModel describes the http block
+type HTTPModel struct {
+	RequestTimeout      types.String `tfsdk:"request_timeout"`
+	DialTimeout         types.String `tfsdk:"dial_timeout"`
+	TLSHandshakeTimeout types.String `tfsdk:"tls_handshake_timeout"`
+	KeepAlive           types.String `tfsdk:"keep_alive"`
+	IdleConnTimeout     types.String `tfsdk:"idle_conn_timeout"`
+	MaxIdleConnsPerHost types.Int64  `tfsdk:"max_idle_conns_per_host"`
+	MaxRetries          types.Int64  `tfsdk:"max_retries"`
+	RetryBackoffBase    types.String `tfsdk:"retry_backoff_base"`
+	RetryBackoffMax     types.String `tfsdk:"retry_backoff_max"`
+	ProxyURL            types.String `tfsdk:"proxy_url"`
+	CompressRequests    types.Bool   `tfsdk:"compress_requests"`
+	MaxResponseSize     types.Int64  `tfsdk:"max_response_size"`
+}
+
+// retryPolicy controls how failed GraphQL requests are retried.
+type retryPolicy struct {
+	MaxRetries  int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// defaultRetryPolicy is used when the http block does not configure retries.
+var defaultRetryPolicy = retryPolicy{
+	MaxRetries:  defaultMaxRetries,
+	BackoffBase: defaultRetryBackoffBase,
+	BackoffMax:  defaultRetryBackoffMax,
+}
+
+// backoff returns the delay before the given retry attempt (zero-based). The
+// delay doubles with every attempt up to BackoffMax, and is jittered so
+// concurrent clients do not retry in lockstep.
+func (r retryPolicy) backoff(attempt int) time.Duration {
+	delay := r.BackoffBase
+	for i := 0; i < attempt && delay < r.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > r.BackoffMax {
+		delay = r.BackoffMax
+	}
+
+	// Equal jitter: wait at least half of the delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseDurationAttribute parses an optional duration attribute of the http
+// block, returning fallback when it is not set.
+func parseDurationAttribute(value types.String, name string, fallback time.Duration, diags *diag.Diagnostics) time.Duration {
+	if !isSet(value) {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil || duration < 0 {
+		diags.AddAttributeError(
+			path.Root("http").AtName(name),
+			"Invalid HTTP Configuration",
+			fmt.Sprintf("`%s` must be a non-negative duration (e.g., '30s'), got %q.", name, value.ValueString()),
+		)
+		return fallback
+	}
+	return duration
+}
+
+// buildRetryPolicy returns the retry settings of the http block.
+func buildRetryPolicy(h *HTTPModel) (retryPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	policy := defaultRetryPolicy
+	if h == nil {
+		return policy, diags
+	}
+
+	if !h.MaxRetries.IsNull() && !h.MaxRetries.IsUnknown() {
+		if h.MaxRetries.ValueInt64() < 0 {
+			diags.AddAttributeError(path.Root("http").AtName("max_retries"), "Invalid HTTP Configuration", "`max_retries` must not be negative.")
+		}
+		policy.MaxRetries = int(h.MaxRetries.ValueInt64())
+	}
+	policy.BackoffBase = parseDurationAttribute(h.RetryBackoffBase, "retry_backoff_base", policy.BackoffBase, &diags)
+	policy.BackoffMax = parseDurationAttribute(h.RetryBackoffMax, "retry_backoff_max", policy.BackoffMax, &diags)
+
+	if policy.BackoffMax < policy.BackoffBase {
+		diags.AddAttributeError(path.Root("http").AtName("retry_backoff_max"), "Invalid HTTP Configuration", "`retry_backoff_max` must not be less than `retry_backoff_base`.")
+	}
+
+	return policy, diags
+}
+
+// buildHTTPClient builds the HTTP client used for every request the provider
+// makes. A single pooled transport is shared by the GraphQL and token
+// endpoints so connections and TLS sessions are reused across requests.
+func buildHTTPClient(data GraphqlProviderModel) (*http.Client, diag.Diagnostics) {
+	tlsConfig, diags := buildTLSConfig(data)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	h := data.HTTP
+	if h == nil {
+		h = &HTTPModel{}
+	}
+
+	requestTimeout := parseDurationAttribute(h.RequestTimeout, "request_timeout", defaultHTTPTimeout, &diags)
+	dialer := &net.Dialer{
+		Timeout:   parseDurationAttribute(h.DialTimeout, "dial_timeout", defaultDialTimeout, &diags),
+		KeepAlive: parseDurationAttribute(h.KeepAlive, "keep_alive", defaultKeepAlive, &diags),
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = parseDurationAttribute(h.TLSHandshakeTimeout, "tls_handshake_timeout", defaultTLSHandshakeTimeout, &diags)
+	transport.IdleConnTimeout = parseDurationAttribute(h.IdleConnTimeout, "idle_conn_timeout", defaultIdleConnTimeout, &diags)
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if !h.MaxIdleConnsPerHost.IsNull() && !h.MaxIdleConnsPerHost.IsUnknown() {
+		if h.MaxIdleConnsPerHost.ValueInt64() < 1 {
+			diags.AddAttributeError(path.Root("http").AtName("max_idle_conns_per_host"), "Invalid HTTP Configuration", "`max_idle_conns_per_host` must be at least 1.")
+		}
+		transport.MaxIdleConnsPerHost = int(h.MaxIdleConnsPerHost.ValueInt64())
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	// An explicit proxy takes precedence over HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	if isSet(h.ProxyURL) {
+		proxyURL, err := url.Parse(h.ProxyURL.ValueString())
+		if err != nil || proxyURL.Host == "" {
+			diags.AddAttributeError(path.Root("http").AtName("proxy_url"), "Invalid HTTP Configuration", fmt.Sprintf("`proxy_url` must be an absolute URL (e.g., 'http://proxy:3128'), got %q.", h.ProxyURL.ValueString()))
+		} else {
+			switch proxyURL.Scheme {
+			case "http", "https", "socks5":
+				transport.Proxy = http.ProxyURL(proxyURL)
+			default:
+				diags.AddAttributeError(path.Root("http").AtName("proxy_url"), "Invalid HTTP Configuration", fmt.Sprintf("`proxy_url` scheme must be http, https or socks5, got %q.", proxyURL.Scheme))
+			}
+		}
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &http.Client{Timeout: requestTimeout, Transport: transport}, diags
+}
@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHTTPTestModel returns an http block with every attribute null.
+func newHTTPTestModel() *HTTPModel {
+	return &HTTPModel{
+		RequestTimeout:      types.StringNull(),
+		DialTimeout:         types.StringNull(),
+		TLSHandshakeTimeout: types.StringNull(),
+		KeepAlive:           types.StringNull(),
+		IdleConnTimeout:     types.StringNull(),
+		MaxIdleConnsPerHost: types.Int64Null(),
+		MaxRetries:          types.Int64Null(),
+		RetryBackoffBase:    types.StringNull(),
+		RetryBackoffMax:     types.StringNull(),
+		ProxyURL:            types.StringNull(),
+		CompressRequests:    types.BoolNull(),
+		MaxResponseSize:     types.Int64Null(),
+	}
+}
+
+func TestBuildHTTPClient_Settings(t *testing.T) {
+	model := newTLSTestModel()
+	model.HTTP = newHTTPTestModel()
+	model.HTTP.RequestTimeout = types.StringValue("45s")
+	model.HTTP.TLSHandshakeTimeout = types.StringValue("5s")
+	model.HTTP.MaxIdleConnsPerHost = types.Int64Value(32)
+	model.HTTP.ProxyURL = types.StringValue("http://proxy.internal:3128")
+
+	client, diags := buildHTTPClient(model)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+
+	assert.Equal(t, 45*time.Second, client.Timeout)
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 32, transport.MaxIdleConnsPerHost)
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/graphql", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", proxyURL.String())
+}
+
+func TestBuildHTTPClient_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*HTTPModel)
+	}{
+		{
+			name:   "invalid timeout",
+			modify: func(h *HTTPModel) { h.RequestTimeout = types.StringValue("soon") },
+		},
+		{
+			name:   "relative proxy URL",
+			modify: func(h *HTTPModel) { h.ProxyURL = types.StringValue("proxy:3128") },
+		},
+		{
+			name:   "unsupported proxy scheme",
+			modify: func(h *HTTPModel) { h.ProxyURL = types.StringValue("ftp://proxy:21") },
+		},
+		{
+			name:   "no idle connections",
+			modify: func(h *HTTPModel) { h.MaxIdleConnsPerHost = types.Int64Value(0) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTLSTestModel()
+			model.HTTP = newHTTPTestModel()
+			tt.modify(model.HTTP)
+
+			_, diags := buildHTTPClient(model)
+			assert.True(t, diags.HasError())
+		})
+	}
+}
+
+func TestBuildRetryPolicy(t *testing.T) {
+	policy, diags := buildRetryPolicy(nil)
+	require.False(t, diags.HasError())
+	assert.Equal(t, defaultRetryPolicy, policy)
+
+	model := newHTTPTestModel()
+	model.MaxRetries = types.Int64Value(2)
+	model.RetryBackoffBase = types.StringValue("200ms")
+	model.RetryBackoffMax = types.StringValue("500ms")
+	policy, diags = buildRetryPolicy(model)
+	require.False(t, diags.HasError())
+	assert.Equal(t, retryPolicy{MaxRetries: 2, BackoffBase: 200 * time.Millisecond, BackoffMax: 500 * time.Millisecond}, policy)
+	for i := 0; i < 20; i++ {
+		first := policy.backoff(0)
+		assert.GreaterOrEqual(t, first, 100*time.Millisecond)
+		assert.LessOrEqual(t, first, 200*time.Millisecond)
+
+		capped := policy.backoff(5)
+		assert.GreaterOrEqual(t, capped, 250*time.Millisecond)
+		assert.LessOrEqual(t, capped, 500*time.Millisecond, "backoff is capped at retry_backoff_max")
+	}
+
+	model.RetryBackoffMax = types.StringValue("100ms")
+	_, diags = buildRetryPolicy(model)
+	assert.True(t, diags.HasError())
+
+	model = newHTTPTestModel()
+	model.MaxRetries = types.Int64Value(-1)
+	_, diags = buildRetryPolicy(model)
+	assert.True(t, diags.HasError())
+}
+
+func TestBuildHTTPClient_ReusesConnections(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, diags := buildHTTPClient(newTLSTestModel())
+	require.False(t, diags.HasError())
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		HTTPClient:     client,
+	}
+	for i := 0; i < 3; i++ {
+		_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { ok }", nil, config)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
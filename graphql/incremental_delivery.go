@@ -0,0 +1,294 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+)
+
+// incrementalDeliveryMediaType is the media type of responses to operations
+// using @defer or @stream, in the format of Apollo Router and graphql-js.
+const incrementalDeliveryMediaType = "multipart/mixed;deferSpec=20220824"
+
+// incrementalBoundary returns the multipart boundary of an incremental
+// delivery response, and false for any other content type.
+func incrementalBoundary(contentType string) (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/mixed" || params["boundary"] == "" {
+		return "", false
+	}
+	return params["boundary"], true
+}
+
+// incrementalPayload is one part of an incremental delivery response. The
+// first part holds the initial result; later parts hold incremental results.
+type incrementalPayload struct {
+	Data        map[string]interface{} `json:"data"`
+	Errors      []interface{}          `json:"errors"`
+	Extensions  map[string]interface{} `json:"extensions"`
+	Pending     []pendingResult        `json:"pending"`
+	Incremental []incrementalResult    `json:"incremental"`
+	Completed   []completedResult      `json:"completed"`
+}
+
+// pendingResult announces a deferred fragment or stream, which later
+// incremental results refer to by ID.
+type pendingResult struct {
+	ID   string        `json:"id"`
+	Path []interface{} `json:"path"`
+}
+
+// incrementalResult is the data of a deferred fragment or items of a stream.
+// Results either carry the path they apply to (deferSpec=20220824) or the ID
+// of a pending result and an optional sub-path (the later spec drafts).
+type incrementalResult struct {
+	ID         string                 `json:"id"`
+	Path       []interface{}          `json:"path"`
+	SubPath    []interface{}          `json:"subPath"`
+	Data       map[string]interface{} `json:"data"`
+	Items      []interface{}          `json:"items"`
+	Errors     []interface{}          `json:"errors"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// completedResult marks a pending result as done, possibly with errors.
+type completedResult struct {
+	ID     string        `json:"id"`
+	Errors []interface{} `json:"errors"`
+}
+
+// incrementalMerger accumulates an incremental delivery response into a
+// single result.
+type incrementalMerger struct {
+	data       map[string]interface{}
+	errors     []interface{}
+	extensions map[string]interface{}
+	// pending maps the IDs of pending results to their paths
+	pending map[string][]interface{}
+}
+
+// mergeIncrementalResponse merges the parts of a multipart/mixed incremental
+// delivery response into the single response the operation would have
+// returned without @defer and @stream.
+func mergeIncrementalResponse(body []byte, boundary string) ([]byte, error) {
+	merger := &incrementalMerger{pending: make(map[string][]interface{})}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read incremental response: %w", err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read incremental response: %w", err)
+		}
+		// Servers send empty parts as heartbeats
+		if len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+
+		var payload incrementalPayload
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
+			return nil, fmt.Errorf("failed to parse incremental payload: %w", err)
+		}
+		if err := merger.apply(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	merged := map[string]interface{}{"data": merger.data}
+	if len(merger.errors) > 0 {
+		merged["errors"] = merger.errors
+	}
+	if len(merger.extensions) > 0 {
+		merged["extensions"] = merger.extensions
+	}
+	return json.Marshal(merged)
+}
+
+// apply merges a payload into the result.
+func (m *incrementalMerger) apply(payload incrementalPayload) error {
+	if payload.Data != nil {
+		if m.data != nil {
+			return fmt.Errorf("received a second initial result")
+		}
+		m.data = payload.Data
+	}
+	m.errors = append(m.errors, payload.Errors...)
+	m.mergeExtensions(payload.Extensions)
+
+	for _, pending := range payload.Pending {
+		m.pending[pending.ID] = pending.Path
+	}
+
+	for _, result := range payload.Incremental {
+		if err := m.applyIncremental(result); err != nil {
+			return err
+		}
+	}
+
+	for _, completed := range payload.Completed {
+		m.errors = append(m.errors, completed.Errors...)
+		delete(m.pending, completed.ID)
+	}
+	return nil
+}
+
+// applyIncremental merges deferred data or appends streamed items.
+func (m *incrementalMerger) applyIncremental(result incrementalResult) error {
+	if m.data == nil {
+		return fmt.Errorf("received an incremental result before the initial result")
+	}
+	m.errors = append(m.errors, result.Errors...)
+	m.mergeExtensions(result.Extensions)
+
+	resultPath := result.Path
+	if result.ID != "" {
+		pendingPath, ok := m.pending[result.ID]
+		if !ok {
+			return fmt.Errorf("received an incremental result for unknown id %q", result.ID)
+		}
+		resultPath = append(append([]interface{}{}, pendingPath...), result.SubPath...)
+	}
+
+	switch {
+	case result.Items != nil:
+		listPath := resultPath
+		start := -1
+		// Without an ID, the path ends with the index of the first item
+		if result.ID == "" {
+			if len(resultPath) == 0 {
+				return fmt.Errorf("streamed items have an empty path")
+			}
+			index, ok := pathIndex(resultPath[len(resultPath)-1])
+			if !ok {
+				return fmt.Errorf("streamed items path %v does not end with an index", resultPath)
+			}
+			listPath = resultPath[:len(resultPath)-1]
+			start = index
+		}
+
+		value, ok := valueAtPath(m.data, listPath)
+		list, isList := value.([]interface{})
+		if !ok || !isList {
+			return fmt.Errorf("no list at path %v for streamed items", listPath)
+		}
+		if start < 0 || start > len(list) {
+			start = len(list)
+		}
+		list = append(list[:start:start], result.Items...)
+		return setAtPath(m.data, listPath, list)
+	case result.Data != nil:
+		value, ok := valueAtPath(m.data, resultPath)
+		target, isObject := value.(map[string]interface{})
+		if !ok || !isObject {
+			return fmt.Errorf("no object at path %v for deferred data", resultPath)
+		}
+		deepMerge(target, result.Data)
+	}
+	return nil
+}
+
+// mergeExtensions adds extensions to the result, later values winning.
+func (m *incrementalMerger) mergeExtensions(extensions map[string]interface{}) {
+	if len(extensions) == 0 {
+		return
+	}
+	if m.extensions == nil {
+		m.extensions = make(map[string]interface{})
+	}
+	deepMerge(m.extensions, extensions)
+}
+
+// deepMerge merges source into target, recursing into objects present in
+// both.
+func deepMerge(target, source map[string]interface{}) {
+	for key, value := range source {
+		sourceObject, sourceIsObject := value.(map[string]interface{})
+		targetObject, targetIsObject := target[key].(map[string]interface{})
+		if sourceIsObject && targetIsObject {
+			deepMerge(targetObject, sourceObject)
+			continue
+		}
+		target[key] = value
+	}
+}
+
+// pathIndex returns a path segment as a list index.
+func pathIndex(segment interface{}) (int, bool) {
+	switch index := segment.(type) {
+	case json.Number:
+		value, err := index.Int64()
+		return int(value), err == nil && value >= 0
+	case float64:
+		return int(index), index >= 0 && index == float64(int(index))
+	default:
+		return 0, false
+	}
+}
+
+// valueAtPath returns the value at a response path.
+func valueAtPath(root interface{}, responsePath []interface{}) (interface{}, bool) {
+	current := root
+	for _, segment := range responsePath {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			key, ok := segment.(string)
+			if !ok {
+				return nil, false
+			}
+			current, ok = node[key]
+			if !ok {
+				return nil, false
+			}
+		case []interface{}:
+			index, ok := pathIndex(segment)
+			if !ok || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setAtPath replaces the value at a non-empty response path.
+func setAtPath(root map[string]interface{}, responsePath []interface{}, value interface{}) error {
+	if len(responsePath) == 0 {
+		return fmt.Errorf("cannot replace the root of the result")
+	}
+	parent, ok := valueAtPath(root, responsePath[:len(responsePath)-1])
+	if !ok {
+		return fmt.Errorf("no value at path %v", responsePath)
+	}
+	last := responsePath[len(responsePath)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		key, ok := last.(string)
+		if !ok {
+			return fmt.Errorf("invalid path %v", responsePath)
+		}
+		node[key] = value
+	case []interface{}:
+		index, ok := pathIndex(last)
+		if !ok || index >= len(node) {
+			return fmt.Errorf("invalid path %v", responsePath)
+		}
+		node[index] = value
+	default:
+		return fmt.Errorf("no value at path %v", responsePath)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartMixed encodes payloads as an incremental delivery response body.
+func multipartMixed(payloads ...string) string {
+	var b strings.Builder
+	for _, payload := range payloads {
+		b.WriteString("\r\n--graphql\r\nContent-Type: application/json; charset=utf-8\r\n\r\n")
+		b.WriteString(payload)
+	}
+	b.WriteString("\r\n--graphql--\r\n")
+	return b.String()
+}
+
+func TestMergeIncrementalResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		payloads  []string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name: "defer with paths",
+			payloads: []string{
+				`{"data":{"user":{"id":"1","profile":{"name":"Ada"}}},"hasNext":true}`,
+				`{}`,
+				`{"incremental":[{"data":{"profile":{"bio":"Analyst"}},"path":["user"],"label":"Profile"}],"hasNext":false}`,
+			},
+			expected: `{"data":{"user":{"id":"1","profile":{"name":"Ada","bio":"Analyst"}}}}`,
+		},
+		{
+			name: "stream with paths",
+			payloads: []string{
+				`{"data":{"repos":[{"id":1}]},"hasNext":true}`,
+				`{"incremental":[{"items":[{"id":2},{"id":3}],"path":["repos",1]}],"hasNext":true}`,
+				`{"incremental":[{"items":[{"id":4}],"path":["repos",3],"errors":[{"message":"slow","path":["repos",3]}]}],"hasNext":false}`,
+			},
+			expected: `{"data":{"repos":[{"id":1},{"id":2},{"id":3},{"id":4}]},"errors":[{"message":"slow","path":["repos",3]}]}`,
+		},
+		{
+			name: "pending ids",
+			payloads: []string{
+				`{"data":{"user":{"id":"1","settings":{"id":"s1"},"repos":[]}},"pending":[{"id":"0","path":["user"]},{"id":"1","path":["user","repos"]}],"hasNext":true}`,
+				`{"incremental":[{"id":"0","data":{"name":"Ada"}},{"id":"0","subPath":["settings"],"data":{"theme":"dark"}}],"completed":[{"id":"0"}],"hasNext":true}`,
+				`{"incremental":[{"id":"1","items":["a","b"]}],"completed":[{"id":"1","errors":[{"message":"stream failed"}]}],"extensions":{"cost":3},"hasNext":false}`,
+			},
+			expected: `{"data":{"user":{"id":"1","name":"Ada","settings":{"id":"s1","theme":"dark"},"repos":["a","b"]}},"errors":[{"message":"stream failed"}],"extensions":{"cost":3}}`,
+		},
+		{
+			name:      "unknown id",
+			payloads:  []string{`{"data":{"user":null},"hasNext":true}`, `{"incremental":[{"id":"7","data":{"name":"Ada"}}],"hasNext":false}`},
+			expectErr: true,
+		},
+		{
+			name:      "incremental before initial",
+			payloads:  []string{`{"incremental":[{"data":{"name":"Ada"},"path":["user"]}],"hasNext":false}`},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeIncrementalResponse([]byte(multipartMixed(tt.payloads...)), "graphql")
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(merged))
+		})
+	}
+}
+
+func TestSendGraphQLRequest_IncrementalDelivery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept"), "multipart/mixed")
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="graphql"; deferSpec=20220824`)
+		_, _ = w.Write([]byte(multipartMixed(
+			`{"data":{"user":{"id":"1"}},"hasNext":true}`,
+			`{"incremental":[{"data":{"email":"ada@example.com"},"path":["user"]}],"hasNext":false}`,
+		)))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+	}
+	resp, body, _, diags := executeSingleGraphQLRequest(context.Background(), "query { user { id ... @defer { email } } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.JSONEq(t, `{"data":{"user":{"id":"1","email":"ada@example.com"}}}`, string(body))
+	assert.Equal(t, "ada@example.com", resp.Data["user"].(map[string]interface{})["email"])
+}
+
+func TestSendGraphQLRequest_IncrementalDeliveryNotModified(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			// The 304 does not repeat the multipart content type
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="graphql"; deferSpec=20220824`)
+		_, _ = w.Write([]byte(multipartMixed(
+			`{"data":{"user":{"id":"1"}},"hasNext":true}`,
+			`{"incremental":[{"data":{"email":"ada@example.com"},"path":["user"]}],"hasNext":false}`,
+		)))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		QueryMethod:    http.MethodGet,
+		ETags:          newETagCache(),
+	}
+	for i := 0; i < 2; i++ {
+		_, body, _, diags := executeSingleGraphQLRequest(context.Background(), "query { user { id ... @defer { email } } }", nil, config)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		assert.JSONEq(t, `{"data":{"user":{"id":"1","email":"ada@example.com"}}}`, string(body))
+	}
+	assert.Equal(t, 2, requests)
+}
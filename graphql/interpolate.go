@@ -0,0 +1,83 @@
+package graphql
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// secretReferencePattern matches ${env:NAME} and ${file:/path} references.
+var secretReferencePattern = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// interpolateSecretReferences replaces ${env:NAME} references with the value of
+// the environment variable and ${file:/path} references with the contents of the
+// file (without trailing newlines). Every reference must resolve to a value.
+func interpolateSecretReferences(value string) (string, error) {
+	var errs []string
+
+	result := secretReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		parts := secretReferencePattern.FindStringSubmatch(match)
+		source, name := parts[1], strings.TrimSpace(parts[2])
+
+		if name == "" {
+			errs = append(errs, fmt.Sprintf("reference %s does not name a %s", match, source))
+			return match
+		}
+
+		switch source {
+		case "env":
+			envValue, ok := os.LookupEnv(name)
+			if !ok {
+				errs = append(errs, fmt.Sprintf("environment variable %s referenced by %s is not set", name, match))
+				return match
+			}
+			return envValue
+		default:
+			contents, err := os.ReadFile(name)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("unable to read file referenced by %s: %v", match, err))
+				return match
+			}
+			return strings.TrimRight(string(contents), "\r\n")
+		}
+	})
+
+	if len(errs) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// resolveSecretReferences interpolates secret references in an attribute value,
+// reporting unresolved references against the attribute path.
+func resolveSecretReferences(attributePath path.Path, value string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	result, err := interpolateSecretReferences(value)
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"Unresolved Secret Reference",
+			fmt.Sprintf("%s. Note that Terraform interpolates ${...} itself, so references must be written as $${env:NAME} or $${file:/path} in configuration.", err),
+		)
+		return "", diags
+	}
+	return result, diags
+}
+
+// resolveSecretReferencesInMap interpolates secret references in every value of a map attribute.
+func resolveSecretReferencesInMap(attributePath path.Path, values map[string]string) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	resolved := make(map[string]string, len(values))
+	for k, v := range values {
+		value, valueDiags := resolveSecretReferences(attributePath.AtMapKey(k), v)
+		diags.Append(valueDiags...)
+		resolved[k] = value
+	}
+	return resolved, diags
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateSecretReferences(t *testing.T) {
+	t.Setenv("GRAPHQL_TEST_CLIENT_ID", "my-client")
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("s3cr3t\n"), 0o600))
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "no references",
+			value:    "Bearer static",
+			expected: "Bearer static",
+		},
+		{
+			name:     "environment variable",
+			value:    "client_id=${env:GRAPHQL_TEST_CLIENT_ID}",
+			expected: "client_id=my-client",
+		},
+		{
+			name:     "file contents without trailing newline",
+			value:    "Bearer ${file:" + secretFile + "}",
+			expected: "Bearer s3cr3t",
+		},
+		{
+			name:     "multiple references",
+			value:    `{"id":"${env:GRAPHQL_TEST_CLIENT_ID}","secret":"${file:` + secretFile + `}"}`,
+			expected: `{"id":"my-client","secret":"s3cr3t"}`,
+		},
+		{
+			name:     "terraform variable syntax is left alone",
+			value:    "${var.client_id}",
+			expected: "${var.client_id}",
+		},
+		{
+			name:        "unset environment variable",
+			value:       "${env:GRAPHQL_TEST_UNSET_VARIABLE}",
+			expectError: true,
+		},
+		{
+			name:        "missing file",
+			value:       "${file:" + filepath.Join(t.TempDir(), "missing") + "}",
+			expectError: true,
+		},
+		{
+			name:        "empty reference",
+			value:       "${env:}",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := interpolateSecretReferences(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestResolveSecretReferencesInMap(t *testing.T) {
+	t.Setenv("GRAPHQL_TEST_TOKEN", "abc123")
+
+	resolved, diags := resolveSecretReferencesInMap(path.Root("headers"), map[string]string{
+		"Authorization": "Bearer ${env:GRAPHQL_TEST_TOKEN}",
+		"X-Static":      "value",
+	})
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, "Bearer abc123", resolved["Authorization"])
+	assert.Equal(t, "value", resolved["X-Static"])
+
+	_, diags = resolveSecretReferencesInMap(path.Root("headers"), map[string]string{
+		"Authorization": "Bearer ${env:GRAPHQL_TEST_UNSET_VARIABLE}",
+	})
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), "GRAPHQL_TEST_UNSET_VARIABLE")
+}
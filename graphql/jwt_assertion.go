@@ -0,0 +1,249 @@
+package graphql
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// JWT assertion modes defined by RFC 7523.
+const (
+	// jwtAssertionModePrivateKeyJWT authenticates the client with a signed
+	// JWT (RFC 7523 section 2.2), as in OpenID Connect private_key_jwt.
+	jwtAssertionModePrivateKeyJWT = "private_key_jwt"
+	// jwtAssertionModeJWTBearer uses the signed JWT itself as the
+	// authorization grant (RFC 7523 section 2.1).
+	jwtAssertionModeJWTBearer = "jwt_bearer"
+
+	jwtClientAssertionType  = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	jwtBearerGrantType      = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	defaultJWTAssertionLife = 5 * time.Minute
+)
+
+// OAuth2JWTAssertionModel describes the oauth2_jwt_assertion block
+type OAuth2JWTAssertionModel struct {
+	PrivateKey types.String `tfsdk:"private_key"`
+	Algorithm  types.String `tfsdk:"algorithm"`
+	KeyID      types.String `tfsdk:"key_id"`
+	Mode       types.String `tfsdk:"mode"`
+	Issuer     types.String `tfsdk:"issuer"`
+	Subject    types.String `tfsdk:"subject"`
+	Audience   types.String `tfsdk:"audience"`
+	Lifetime   types.String `tfsdk:"lifetime"`
+	Claims     types.Map    `tfsdk:"claims"`
+}
+
+// validateJWTAssertion checks that the oauth2_jwt_assertion block is complete.
+func validateJWTAssertion(ja *OAuth2JWTAssertionModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !isSet(ja.PrivateKey) || !isSet(ja.Issuer) {
+		diags.AddError(
+			"Incomplete OAuth2 JWT assertion configuration",
+			"`private_key` and `issuer` must both be set in the `oauth2_jwt_assertion` block.",
+		)
+	}
+
+	switch ja.Algorithm.ValueString() {
+	case "", "RS256", "ES256":
+	default:
+		diags.AddError(
+			"Invalid JWT signing algorithm",
+			fmt.Sprintf("`algorithm` must be either \"RS256\" or \"ES256\", got %q.", ja.Algorithm.ValueString()),
+		)
+	}
+
+	switch ja.Mode.ValueString() {
+	case "", jwtAssertionModePrivateKeyJWT, jwtAssertionModeJWTBearer:
+	default:
+		diags.AddError(
+			"Invalid JWT assertion mode",
+			fmt.Sprintf("`mode` must be either %q or %q, got %q.", jwtAssertionModePrivateKeyJWT, jwtAssertionModeJWTBearer, ja.Mode.ValueString()),
+		)
+	}
+
+	if isSet(ja.Lifetime) {
+		if lifetime, err := time.ParseDuration(ja.Lifetime.ValueString()); err != nil || lifetime <= 0 {
+			diags.AddError(
+				"Invalid JWT assertion lifetime",
+				fmt.Sprintf("`lifetime` must be a positive duration (e.g., '5m'), got %q.", ja.Lifetime.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
+
+// buildJWTAssertionBody signs a JWT assertion and adds it to the form-encoded
+// token request body, alongside any parameters already present in body.
+func buildJWTAssertionBody(ctx context.Context, ja *OAuth2JWTAssertionModel, tokenURL, body string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	form, err := url.ParseQuery(body)
+	if err != nil {
+		diags.AddError("OAuth2 JWT Assertion Error", fmt.Sprintf("oauth2_rest_body must be form-encoded when oauth2_jwt_assertion is set: %v", err))
+		return "", diags
+	}
+
+	keyPEM, resolveDiags := resolveSecretReferences(path.Root("oauth2_jwt_assertion").AtName("private_key"), ja.PrivateKey.ValueString())
+	diags.Append(resolveDiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	audience := tokenURL
+	if isSet(ja.Audience) {
+		audience = ja.Audience.ValueString()
+	}
+	subject := ja.Issuer.ValueString()
+	if isSet(ja.Subject) {
+		subject = ja.Subject.ValueString()
+	}
+	lifetime := defaultJWTAssertionLife
+	if isSet(ja.Lifetime) {
+		lifetime, _ = time.ParseDuration(ja.Lifetime.ValueString())
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		diags.AddError("OAuth2 JWT Assertion Error", fmt.Sprintf("failed to generate jti: %v", err))
+		return "", diags
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{}
+	if !ja.Claims.IsNull() && !ja.Claims.IsUnknown() {
+		extra := make(map[string]string)
+		diags.Append(ja.Claims.ElementsAs(ctx, &extra, false)...)
+		if diags.HasError() {
+			return "", diags
+		}
+		for k, v := range extra {
+			claims[k] = v
+		}
+	}
+	claims["iss"] = ja.Issuer.ValueString()
+	claims["sub"] = subject
+	claims["aud"] = audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(lifetime).Unix()
+	claims["jti"] = hex.EncodeToString(jti)
+
+	algorithm := ja.Algorithm.ValueString()
+	if algorithm == "" {
+		algorithm = "RS256"
+	}
+
+	assertion, err := signJWT(algorithm, ja.KeyID.ValueString(), claims, []byte(keyPEM))
+	if err != nil {
+		diags.AddAttributeError(path.Root("oauth2_jwt_assertion").AtName("private_key"), "OAuth2 JWT Signing Error", err.Error())
+		return "", diags
+	}
+
+	if ja.Mode.ValueString() == jwtAssertionModeJWTBearer {
+		form.Set("grant_type", jwtBearerGrantType)
+		form.Set("assertion", assertion)
+	} else {
+		if form.Get("grant_type") == "" {
+			form.Set("grant_type", "client_credentials")
+		}
+		form.Set("client_assertion_type", jwtClientAssertionType)
+		form.Set("client_assertion", assertion)
+	}
+
+	return form.Encode(), diags
+}
+
+// signJWT encodes and signs a compact JWS with RS256 or ES256.
+func signJWT(algorithm, keyID string, claims map[string]interface{}, keyPEM []byte) (string, error) {
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	header := map[string]string{"alg": algorithm, "typ": "JWT"}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch algorithm {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("RS256 requires an RSA private key, got %T", key)
+		}
+		signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign JWT: %w", err)
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return "", fmt.Errorf("ES256 requires a P-256 ECDSA private key")
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign JWT: %w", err)
+		}
+		// JWS uses the fixed-width R || S encoding rather than ASN.1
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	default:
+		return "", fmt.Errorf("unsupported JWT signing algorithm %q", algorithm)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM-encoded PKCS#8, PKCS#1 or SEC 1 private key.
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("private_key does not contain a PEM block")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("private_key is not a PKCS#8, PKCS#1 or SEC 1 private key")
+}
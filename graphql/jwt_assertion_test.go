@@ -0,0 +1,232 @@
+package graphql
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyJWT checks the signature of a compact JWS and returns its header and claims.
+func verifyJWT(token string, publicKey crypto.PublicKey) (map[string]interface{}, map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("malformed JWT")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, nil, err
+		}
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return nil, nil, errors.New("invalid ES256 signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return nil, nil, errors.New("invalid ES256 signature")
+		}
+	}
+
+	var header, claims map[string]interface{}
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, nil, err
+	}
+	return header, claims, nil
+}
+
+func marshalPKCS8(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestPerformRestOAuth2Login_JWTAssertion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		mode      string
+		keyPEM    string
+		publicKey crypto.PublicKey
+	}{
+		{
+			name:      "RS256 private_key_jwt",
+			algorithm: "RS256",
+			mode:      jwtAssertionModePrivateKeyJWT,
+			keyPEM:    marshalPKCS8(t, rsaKey),
+			publicKey: &rsaKey.PublicKey,
+		},
+		{
+			name:      "ES256 jwt_bearer",
+			algorithm: "ES256",
+			mode:      jwtAssertionModeJWTBearer,
+			keyPEM:    marshalPKCS8(t, ecKey),
+			publicKey: &ecKey.PublicKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokenURL string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+
+				var assertion string
+				if tt.mode == jwtAssertionModeJWTBearer {
+					assert.Equal(t, jwtBearerGrantType, r.PostForm.Get("grant_type"))
+					assertion = r.PostForm.Get("assertion")
+				} else {
+					assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+					assert.Equal(t, jwtClientAssertionType, r.PostForm.Get("client_assertion_type"))
+					assertion = r.PostForm.Get("client_assertion")
+				}
+				assert.Equal(t, "read", r.PostForm.Get("scope"))
+
+				header, claims, err := verifyJWT(assertion, tt.publicKey)
+				if err != nil {
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+					return
+				}
+				assert.Equal(t, tt.algorithm, header["alg"])
+				assert.Equal(t, "key-1", header["kid"])
+				assert.Equal(t, "my-client", claims["iss"])
+				assert.Equal(t, "my-client", claims["sub"])
+				assert.Equal(t, tokenURL, claims["aud"])
+				assert.Equal(t, "acme", claims["tenant"])
+				assert.NotEmpty(t, claims["jti"])
+				assert.Greater(t, claims["exp"], claims["iat"])
+
+				_, _ = w.Write([]byte(`{"access_token":"abc123","expires_in":300}`))
+			}))
+			defer server.Close()
+			tokenURL = server.URL + "/token"
+
+			data := GraphqlProviderModel{
+				OAuth2RestURL:       types.StringValue(tokenURL),
+				OAuth2RestBody:      types.StringValue("scope=read"),
+				OAuth2RestTokenPath: types.StringValue("access_token"),
+				OAuth2JWTAssertion: &OAuth2JWTAssertionModel{
+					PrivateKey: types.StringValue(tt.keyPEM),
+					Algorithm:  types.StringValue(tt.algorithm),
+					KeyID:      types.StringValue("key-1"),
+					Mode:       types.StringValue(tt.mode),
+					Issuer:     types.StringValue("my-client"),
+					Subject:    types.StringNull(),
+					Audience:   types.StringNull(),
+					Lifetime:   types.StringValue("2m"),
+					Claims: types.MapValueMust(types.StringType, map[string]attr.Value{
+						"tenant": types.StringValue("acme"),
+					}),
+				},
+			}
+			require.False(t, validateJWTAssertion(data.OAuth2JWTAssertion).HasError())
+
+			p := &GraphqlProvider{}
+			token, expiry, diags := p.performRestOAuth2Login(context.Background(), &graphqlProviderConfig{}, data)
+
+			require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+			assert.Equal(t, "abc123", token)
+			assert.False(t, expiry.IsZero())
+		})
+	}
+}
+
+func TestSignJWT_KeyMismatch(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = signJWT("RS256", "", map[string]interface{}{"iss": "x"}, []byte(marshalPKCS8(t, ecKey)))
+	assert.Error(t, err)
+
+	_, err = signJWT("RS256", "", map[string]interface{}{"iss": "x"}, []byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestValidateJWTAssertion(t *testing.T) {
+	newModel := func() *OAuth2JWTAssertionModel {
+		return &OAuth2JWTAssertionModel{
+			PrivateKey: types.StringValue("pem"),
+			Algorithm:  types.StringNull(),
+			Mode:       types.StringNull(),
+			Issuer:     types.StringValue("my-client"),
+			Lifetime:   types.StringNull(),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(*OAuth2JWTAssertionModel)
+		expectError bool
+	}{
+		{
+			name:   "defaults",
+			modify: func(m *OAuth2JWTAssertionModel) {},
+		},
+		{
+			name:        "missing issuer",
+			modify:      func(m *OAuth2JWTAssertionModel) { m.Issuer = types.StringNull() },
+			expectError: true,
+		},
+		{
+			name:        "unsupported algorithm",
+			modify:      func(m *OAuth2JWTAssertionModel) { m.Algorithm = types.StringValue("HS256") },
+			expectError: true,
+		},
+		{
+			name:        "unknown mode",
+			modify:      func(m *OAuth2JWTAssertionModel) { m.Mode = types.StringValue("saml") },
+			expectError: true,
+		},
+		{
+			name:        "invalid lifetime",
+			modify:      func(m *OAuth2JWTAssertionModel) { m.Lifetime = types.StringValue("-1m") },
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newModel()
+			tt.modify(model)
+			assert.Equal(t, tt.expectError, validateJWTAssertion(model).HasError())
+		})
+	}
+}
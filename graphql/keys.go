@@ -12,13 +12,14 @@ import (
 
 // computeMutationVariableKeys computes mutation variable keys from a key map and a response object.
 // It extracts values from the response JSON using the provided paths and returns them as a map.
-func computeMutationVariableKeys(keyMaps map[string]interface{}, responseJSON string) (map[string]string, error) {
+// Extracted values may be credentials (e.g. login tokens), so only paths are logged.
+func computeMutationVariableKeys(ctx context.Context, keyMaps map[string]interface{}, responseJSON string) (map[string]string, error) {
 	mvks := make(map[string]string)
 
 	// Debug: Log the response structure for troubleshooting
-	tflog.Debug(context.Background(), "Processing GraphQL response", map[string]any{
-		"responseLength":  len(responseJSON),
-		"responsePreview": responseJSON[:min(500, len(responseJSON))],
+	tflog.Debug(ctx, "Processing GraphQL response", map[string]any{
+		"responseLength": len(responseJSON),
+		"responseKeys":   getTopLevelKeys(responseJSON),
 	})
 
 	for k, v := range keyMaps {
@@ -41,7 +42,7 @@ func computeMutationVariableKeys(keyMaps map[string]interface{}, responseJSON st
 				if !result.Exists() {
 					result = gjson.Get(responseJSON, "paginatedData.0."+path)
 					if !result.Exists() {
-						tflog.Debug(context.Background(), "Path not found, logging available paths", map[string]any{
+						tflog.Debug(ctx, "Path not found, logging available paths", map[string]any{
 							"searchedPath":  fullPath,
 							"paginatedPath": paginatedPath,
 							"fallbackPath":  path,
@@ -54,10 +55,9 @@ func computeMutationVariableKeys(keyMaps map[string]interface{}, responseJSON st
 		}
 
 		mvks[k] = result.String()
-		tflog.Debug(context.Background(), "Successfully extracted value", map[string]any{
-			"key":   k,
-			"path":  path,
-			"value": result.String(),
+		tflog.Debug(ctx, "Successfully extracted value", map[string]any{
+			"key":  k,
+			"path": path,
 		})
 	}
 	return mvks, nil
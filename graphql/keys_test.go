@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -66,7 +67,7 @@ func TestComputeMutationVariableKeys(t *testing.T) {
 	}
 
 	for i, c := range cases {
-		m, err := computeMutationVariableKeys(c.computeKeys, c.body)
+		m, err := computeMutationVariableKeys(context.Background(), c.computeKeys, c.body)
 		if c.expectedErrorMsg != "" {
 			assert.Error(t, err, fmt.Sprintf("test case: %d", i))
 			assert.EqualError(t, err, c.expectedErrorMsg, fmt.Sprintf("test case: %d", i))
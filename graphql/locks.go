@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// mutationLocks serializes graphql_mutation operations that share a lock_key
+// across every provider instance in this process.
+var mutationLocks = newKeyedMutex()
+
+// keyedMutex is a set of named locks that can be acquired with a context.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is a single named lock and the number of callers holding or
+// waiting for it, so it can be removed once unused.
+type keyedLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// newKeyedMutex returns an empty set of named locks.
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// Lock acquires the named lock, blocking until it is free or the context is
+// cancelled. The returned function releases it.
+func (k *keyedMutex) Lock(ctx context.Context, key string) (func(), error) {
+	k.mu.Lock()
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &keyedLock{ch: make(chan struct{}, 1)}
+		k.locks[key] = lock
+	}
+	lock.refs++
+	k.mu.Unlock()
+
+	select {
+	case lock.ch <- struct{}{}:
+		return func() {
+			<-lock.ch
+			k.release(key, lock)
+		}, nil
+	case <-ctx.Done():
+		k.release(key, lock)
+		return nil, ctx.Err()
+	}
+}
+
+// release drops a reference to the named lock, removing it when unused.
+func (k *keyedMutex) release(key string, lock *keyedLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(k.locks, key)
+	}
+}
+
+// requestSemaphore limits the number of GraphQL requests in flight.
+type requestSemaphore chan struct{}
+
+// newRequestSemaphore returns a semaphore allowing size concurrent requests,
+// or nil, meaning unlimited, when size is not positive.
+func newRequestSemaphore(size int) requestSemaphore {
+	if size <= 0 {
+		return nil
+	}
+	return make(requestSemaphore, size)
+}
+
+// Acquire blocks until a request slot is free or the context is cancelled.
+// The returned function releases the slot.
+func (s requestSemaphore) Acquire(ctx context.Context) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case s <- struct{}{}:
+		return func() { <-s }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
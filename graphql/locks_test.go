@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyTracker records the highest number of callers inside a section.
+type concurrencyTracker struct {
+	current int32
+	max     int32
+}
+
+func (c *concurrencyTracker) enter() {
+	current := atomic.AddInt32(&c.current, 1)
+	for {
+		highest := atomic.LoadInt32(&c.max)
+		if current <= highest || atomic.CompareAndSwapInt32(&c.max, highest, current) {
+			return
+		}
+	}
+}
+
+func (c *concurrencyTracker) leave() {
+	atomic.AddInt32(&c.current, -1)
+}
+
+func TestKeyedMutex(t *testing.T) {
+	locks := newKeyedMutex()
+	trackers := map[string]*concurrencyTracker{"a": {}, "b": {}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for key, tracker := range trackers {
+			wg.Add(1)
+			go func(key string, tracker *concurrencyTracker) {
+				defer wg.Done()
+				unlock, err := locks.Lock(context.Background(), key)
+				require.NoError(t, err)
+				tracker.enter()
+				time.Sleep(time.Millisecond)
+				tracker.leave()
+				unlock()
+			}(key, tracker)
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), trackers["a"].max)
+	assert.Equal(t, int32(1), trackers["b"].max)
+	assert.Empty(t, locks.locks, "unused locks are removed")
+}
+
+func TestKeyedMutex_Cancelled(t *testing.T) {
+	locks := newKeyedMutex()
+
+	unlock, err := locks.Lock(context.Background(), "parent")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = locks.Lock(ctx, "parent")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	assert.Empty(t, locks.locks)
+}
+
+func TestRequestSemaphore_LimitsInFlightRequests(t *testing.T) {
+	tracker := &concurrencyTracker{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker.enter()
+		defer tracker.leave()
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		RequestSlots:   newRequestSemaphore(2),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { ok }", nil, config)
+			assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&tracker.max), int32(2))
+}
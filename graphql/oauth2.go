@@ -0,0 +1,196 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// OAuth2 client authentication styles supported by the client credentials grant.
+const (
+	oauth2AuthStyleHeader = "header"
+	oauth2AuthStyleBody   = "body"
+)
+
+// OAuth2ClientCredentialsModel describes the oauth2_client_credentials block
+type OAuth2ClientCredentialsModel struct {
+	TokenURL     types.String `tfsdk:"token_url"`
+	ClientID     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	Scopes       types.List   `tfsdk:"scopes"`
+	Audience     types.String `tfsdk:"audience"`
+	AuthStyle    types.String `tfsdk:"auth_style"`
+}
+
+// oauth2TokenResponse is the successful token endpoint response defined in RFC 6749 section 5.1.
+type oauth2TokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	TokenType   string      `json:"token_type"`
+	ExpiresIn   json.Number `json:"expires_in"`
+	Scope       string      `json:"scope"`
+}
+
+// oauth2ErrorResponse is the token endpoint error response defined in RFC 6749 section 5.2.
+type oauth2ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ErrorURI         string `json:"error_uri"`
+}
+
+// describeTokenError describes a failed token request by its status code and
+// the RFC 6749 error fields. The rest of the body is left out, as token
+// endpoints often echo the client ID, assertion or other credentials.
+func describeTokenError(statusCode int, body []byte) string {
+	detail := fmt.Sprintf("token endpoint returned HTTP %d", statusCode)
+	var errResp oauth2ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+		detail += ": " + errResp.Error
+		if errResp.ErrorDescription != "" {
+			detail += ": " + errResp.ErrorDescription
+		}
+	}
+	return detail
+}
+
+// validateClientCredentials checks that the oauth2_client_credentials block is complete.
+func validateClientCredentials(cc *OAuth2ClientCredentialsModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if cc.TokenURL.IsNull() || cc.TokenURL.IsUnknown() || cc.TokenURL.ValueString() == "" ||
+		cc.ClientID.IsNull() || cc.ClientID.IsUnknown() || cc.ClientID.ValueString() == "" ||
+		cc.ClientSecret.IsNull() || cc.ClientSecret.IsUnknown() {
+		diags.AddError(
+			"Incomplete OAuth2 client credentials configuration",
+			"`token_url`, `client_id` and `client_secret` must all be set in the `oauth2_client_credentials` block.",
+		)
+		return diags
+	}
+
+	if !cc.AuthStyle.IsNull() && !cc.AuthStyle.IsUnknown() {
+		switch cc.AuthStyle.ValueString() {
+		case oauth2AuthStyleHeader, oauth2AuthStyleBody:
+		default:
+			diags.AddError(
+				"Invalid OAuth2 auth style",
+				fmt.Sprintf("`auth_style` must be either %q or %q, got %q.", oauth2AuthStyleHeader, oauth2AuthStyleBody, cc.AuthStyle.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
+
+// performClientCredentialsLogin requests an access token using the OAuth2 client
+// credentials grant (RFC 6749 section 4.4) and returns it with its expiry.
+func (p *GraphqlProvider) performClientCredentialsLogin(ctx context.Context, config *graphqlProviderConfig, cc *OAuth2ClientCredentialsModel) (string, time.Time, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tflog.Debug(ctx, "Performing OAuth2 client credentials login", map[string]any{
+		"tokenURL": cc.TokenURL.ValueString(),
+	})
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	if !cc.Scopes.IsNull() && !cc.Scopes.IsUnknown() {
+		var scopes []string
+		diags.Append(cc.Scopes.ElementsAs(ctx, &scopes, false)...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
+		}
+		if len(scopes) > 0 {
+			form.Set("scope", strings.Join(scopes, " "))
+		}
+	}
+
+	if !cc.Audience.IsNull() && !cc.Audience.IsUnknown() && cc.Audience.ValueString() != "" {
+		form.Set("audience", cc.Audience.ValueString())
+	}
+
+	authStyle := oauth2AuthStyleHeader
+	if !cc.AuthStyle.IsNull() && !cc.AuthStyle.IsUnknown() {
+		authStyle = cc.AuthStyle.ValueString()
+	}
+
+	clientID, resolveDiags := resolveSecretReferences(path.Root("oauth2_client_credentials").AtName("client_id"), cc.ClientID.ValueString())
+	diags.Append(resolveDiags...)
+	clientSecret, resolveDiags := resolveSecretReferences(path.Root("oauth2_client_credentials").AtName("client_secret"), cc.ClientSecret.ValueString())
+	diags.Append(resolveDiags...)
+	if diags.HasError() {
+		return "", time.Time{}, diags
+	}
+	if authStyle == oauth2AuthStyleBody {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.TokenURL.ValueString(), strings.NewReader(form.Encode()))
+	if err != nil {
+		diags.AddError("OAuth2 Request Creation Error", fmt.Sprintf("failed to create token request: %v", err))
+		return "", time.Time{}, diags
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	// RFC 6749 section 2.3.1 requires the client credentials to be form-encoded
+	// before they are used as the HTTP Basic username and password.
+	if authStyle == oauth2AuthStyleHeader {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := config.httpClient().Do(req)
+	if err != nil {
+		diags.AddError("OAuth2 Request Error", fmt.Sprintf("failed to execute token request: %v", err))
+		return "", time.Time{}, diags
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, readDiags := config.readResponse(resp, cc.TokenURL.ValueString())
+	diags.Append(readDiags...)
+	if diags.HasError() {
+		return "", time.Time{}, diags
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		diags.AddError("OAuth2 Token Error", describeTokenError(resp.StatusCode, bodyBytes))
+		return "", time.Time{}, diags
+	}
+
+	var tokenResp oauth2TokenResponse
+	if err := json.Unmarshal(bodyBytes, &tokenResp); err != nil {
+		diags.AddError("OAuth2 Response Parsing Error", fmt.Sprintf("failed to parse token response: %v", err))
+		return "", time.Time{}, diags
+	}
+
+	if tokenResp.AccessToken == "" {
+		diags.AddError("OAuth2 Token Error", "token endpoint response did not contain an access_token")
+		return "", time.Time{}, diags
+	}
+
+	if tokenResp.TokenType != "" && !strings.EqualFold(tokenResp.TokenType, "bearer") {
+		diags.AddWarning(
+			"Unexpected OAuth2 Token Type",
+			fmt.Sprintf("token endpoint returned token_type %q; it will be sent as a Bearer token.", tokenResp.TokenType),
+		)
+	}
+
+	expiry, err := parseTokenExpiry(tokenResp.ExpiresIn.String(), time.Now())
+	if err != nil {
+		diags.AddError("OAuth2 Token Expiry Error", err.Error())
+		return "", time.Time{}, diags
+	}
+
+	tflog.Debug(ctx, "OAuth2 client credentials login successful", map[string]any{
+		"expiry": expiry,
+	})
+	return tokenResp.AccessToken, expiry, diags
+}
@@ -0,0 +1,184 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClientCredentialsModel(tokenURL, authStyle string) *OAuth2ClientCredentialsModel {
+	return &OAuth2ClientCredentialsModel{
+		TokenURL:     types.StringValue(tokenURL),
+		ClientID:     types.StringValue("client id"),
+		ClientSecret: types.StringValue("s3cr3t&"),
+		Scopes: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue("read"),
+			types.StringValue("write"),
+		}),
+		Audience:  types.StringValue("https://api.example.com"),
+		AuthStyle: types.StringValue(authStyle),
+	}
+}
+
+func TestPerformClientCredentialsLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		authStyle string
+	}{
+		{
+			name:      "credentials in basic auth header",
+			authStyle: oauth2AuthStyleHeader,
+		},
+		{
+			name:      "credentials in request body",
+			authStyle: oauth2AuthStyleBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+				assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+				assert.Equal(t, "read write", r.PostForm.Get("scope"))
+				assert.Equal(t, "https://api.example.com", r.PostForm.Get("audience"))
+
+				user, pass, hasBasic := r.BasicAuth()
+				if tt.authStyle == oauth2AuthStyleHeader {
+					assert.True(t, hasBasic)
+					assert.Equal(t, "client+id", user)
+					assert.Equal(t, "s3cr3t%26", pass)
+					assert.Empty(t, r.PostForm.Get("client_secret"))
+				} else {
+					assert.False(t, hasBasic)
+					assert.Equal(t, "client id", r.PostForm.Get("client_id"))
+					assert.Equal(t, "s3cr3t&", r.PostForm.Get("client_secret"))
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"abc123","token_type":"Bearer","expires_in":3600}`))
+			}))
+			defer server.Close()
+
+			p := &GraphqlProvider{}
+			token, expiry, diags := p.performClientCredentialsLogin(context.Background(), &graphqlProviderConfig{}, newClientCredentialsModel(server.URL, tt.authStyle))
+
+			require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+			assert.Equal(t, "abc123", token)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), expiry, time.Minute)
+		})
+	}
+}
+
+func TestPerformClientCredentialsLogin_ErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"unknown client"}`))
+	}))
+	defer server.Close()
+
+	p := &GraphqlProvider{}
+	token, _, diags := p.performClientCredentialsLogin(context.Background(), &graphqlProviderConfig{}, newClientCredentialsModel(server.URL, oauth2AuthStyleHeader))
+
+	require.True(t, diags.HasError())
+	assert.Empty(t, token)
+	assert.Contains(t, diags[0].Detail(), "invalid_client: unknown client")
+}
+
+func TestDescribeTokenError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "RFC 6749 error",
+			body:     `{"error":"invalid_client","error_description":"unknown client","client_id":"my-client","client_secret":"s3cr3t"}`,
+			expected: "token endpoint returned HTTP 401: invalid_client: unknown client",
+		},
+		{
+			name:     "error without description",
+			body:     `{"error":"invalid_grant","assertion":"eyJhbGciOi.payload.sig"}`,
+			expected: "token endpoint returned HTTP 401: invalid_grant",
+		},
+		{
+			name:     "other body",
+			body:     `client_secret=s3cr3t rejected`,
+			expected: "token endpoint returned HTTP 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, describeTokenError(http.StatusUnauthorized, []byte(tt.body)))
+		})
+	}
+}
+
+func TestPerformRestOAuth2Login_ErrorOmitsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","received":"client_secret=s3cr3t-value"}`))
+	}))
+	defer server.Close()
+
+	data := GraphqlProviderModel{
+		OAuth2RestURL:       types.StringValue(server.URL),
+		OAuth2RestBody:      types.StringValue("client_secret=s3cr3t-value"),
+		OAuth2RestTokenPath: types.StringValue("access_token"),
+	}
+	p := &GraphqlProvider{}
+	_, _, diags := p.performRestOAuth2Login(context.Background(), &graphqlProviderConfig{}, data)
+
+	require.True(t, diags.HasError())
+	assert.Equal(t, "token endpoint returned HTTP 401: invalid_client", diags[0].Detail())
+}
+
+func TestValidateClientCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		model       *OAuth2ClientCredentialsModel
+		expectError bool
+	}{
+		{
+			name:        "complete configuration",
+			model:       newClientCredentialsModel("https://auth.example.com/token", oauth2AuthStyleBody),
+			expectError: false,
+		},
+		{
+			name: "missing client secret",
+			model: &OAuth2ClientCredentialsModel{
+				TokenURL:     types.StringValue("https://auth.example.com/token"),
+				ClientID:     types.StringValue("id"),
+				ClientSecret: types.StringNull(),
+				Scopes:       types.ListNull(types.StringType),
+				Audience:     types.StringNull(),
+				AuthStyle:    types.StringNull(),
+			},
+			expectError: true,
+		},
+		{
+			name:        "invalid auth style",
+			model:       newClientCredentialsModel("https://auth.example.com/token", "query"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateClientCredentials(tt.model)
+			assert.Equal(t, tt.expectError, diags.HasError())
+		})
+	}
+}
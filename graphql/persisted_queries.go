@@ -0,0 +1,209 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	gqlerrors "github.com/kalenarndt/terraform-provider-graphql/internal/errors"
+)
+
+// Persisted query modes.
+const (
+	// persistedQueriesModeAutomatic sends the hash first and registers the
+	// document when the server does not know it yet (Automatic Persisted Queries).
+	persistedQueriesModeAutomatic = "automatic"
+	// persistedQueriesModeStrict only ever sends hashes from the manifest.
+	persistedQueriesModeStrict = "strict"
+)
+
+// PersistedQueriesModel describes the persisted_queries block
+type PersistedQueriesModel struct {
+	Mode         types.String `tfsdk:"mode"`
+	ManifestFile types.String `tfsdk:"manifest_file"`
+}
+
+// validatePersistedQueries checks that the persisted_queries block is complete.
+func validatePersistedQueries(pq *PersistedQueriesModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	switch pq.Mode.ValueString() {
+	case "", persistedQueriesModeAutomatic:
+	case persistedQueriesModeStrict:
+		if !isSet(pq.ManifestFile) {
+			diags.AddError(
+				"Incomplete persisted queries configuration",
+				"`manifest_file` must be set in the `persisted_queries` block when `mode` is \"strict\".",
+			)
+		}
+	default:
+		diags.AddError(
+			"Invalid persisted queries mode",
+			fmt.Sprintf("`mode` must be either %q or %q, got %q.", persistedQueriesModeAutomatic, persistedQueriesModeStrict, pq.Mode.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// persistedQueries sends operations by hash instead of by document.
+type persistedQueries struct {
+	strict bool
+	// manifest maps normalized documents to their persisted query IDs
+	manifest map[string]string
+	// unsupported is set once the server reports that it does not support
+	// persisted queries, after which documents are sent as usual
+	unsupported atomic.Bool
+}
+
+// newPersistedQueries builds the persisted query support from the
+// persisted_queries block, loading the manifest if one is configured.
+func newPersistedQueries(pq *PersistedQueriesModel) (*persistedQueries, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	p := &persistedQueries{strict: pq.Mode.ValueString() == persistedQueriesModeStrict}
+	if isSet(pq.ManifestFile) {
+		manifest, err := loadPersistedQueryManifest(pq.ManifestFile.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("persisted_queries").AtName("manifest_file"), "Unable to Read Persisted Query Manifest", err.Error())
+			return nil, diags
+		}
+		p.manifest = manifest
+	}
+	return p, diags
+}
+
+// loadPersistedQueryManifest reads a persisted query manifest, either in the
+// Apollo format ({"operations": [{"id": ..., "body": ...}]}) or as a flat
+// object of IDs to documents (as generated by Relay).
+func loadPersistedQueryManifest(manifestPath string) (map[string]string, error) {
+	contents, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", manifestPath, err)
+	}
+
+	var apolloManifest struct {
+		Operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	manifest := make(map[string]string)
+	if err := json.Unmarshal(contents, &apolloManifest); err == nil && len(apolloManifest.Operations) > 0 {
+		for _, operation := range apolloManifest.Operations {
+			manifest[normalizeDocument(operation.Body)] = operation.ID
+		}
+		return manifest, nil
+	}
+
+	var flatManifest map[string]string
+	if err := json.Unmarshal(contents, &flatManifest); err != nil {
+		return nil, fmt.Errorf("%s is neither an Apollo persisted query manifest nor an object of IDs to documents: %w", manifestPath, err)
+	}
+	for id, body := range flatManifest {
+		manifest[normalizeDocument(body)] = id
+	}
+	return manifest, nil
+}
+
+// normalizeDocument collapses whitespace so documents match the manifest
+// regardless of how they are indented in the Terraform configuration.
+func normalizeDocument(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// hash returns the persisted query ID of a document: its manifest ID if it
+// is listed, otherwise (outside strict mode) its SHA-256 hash.
+func (p *persistedQueries) hash(query string) (string, bool) {
+	if id, ok := p.manifest[normalizeDocument(query)]; ok {
+		return id, true
+	}
+	if p.strict {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// execute sends the request by hash. In automatic mode a document the server
+// does not know yet is sent again in full so the server registers it.
+func (p *persistedQueries) execute(ctx context.Context, request graphQLRequest, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, *gqlerrors.RequestError, diag.Diagnostics) {
+	if p.unsupported.Load() {
+		return sendGraphQLRequest(ctx, request, config)
+	}
+
+	hash, ok := p.hash(request.Query)
+	if !ok {
+		var diags diag.Diagnostics
+		diags.AddError(
+			"Persisted Query Not Found",
+			fmt.Sprintf("the operation is not in the persisted query manifest, and only persisted operations may be sent in strict mode: %s", normalizeDocument(request.Query)),
+		)
+		return nil, nil, nil, diags
+	}
+
+	request.Extensions = map[string]interface{}{
+		"persistedQuery": map[string]interface{}{
+			"version":    1,
+			"sha256Hash": hash,
+		},
+	}
+	request.OmitQuery = true
+
+	queryResponse, bodyBytes, requestErr, diags := sendGraphQLRequest(ctx, request, config)
+	if p.strict {
+		return queryResponse, bodyBytes, requestErr, diags
+	}
+
+	switch persistedQueryErrorCode(queryResponse, requestErr) {
+	case "PERSISTED_QUERY_NOT_FOUND":
+		tflog.Debug(ctx, "Persisted query not found, registering it", map[string]any{
+			"sha256Hash": hash,
+		})
+		request.OmitQuery = false
+		return sendGraphQLRequest(ctx, request, config)
+	case "PERSISTED_QUERY_NOT_SUPPORTED":
+		tflog.Debug(ctx, "Server does not support persisted queries, sending documents instead")
+		p.unsupported.Store(true)
+		request.Extensions = nil
+		request.OmitQuery = false
+		return sendGraphQLRequest(ctx, request, config)
+	}
+
+	return queryResponse, bodyBytes, requestErr, diags
+}
+
+// persistedQueryErrorCode returns PERSISTED_QUERY_NOT_FOUND or
+// PERSISTED_QUERY_NOT_SUPPORTED if the server rejected a persisted query,
+// whether it responded with HTTP 200 or an error status.
+func persistedQueryErrorCode(queryResponse *GqlQueryResponse, requestErr *gqlerrors.RequestError) string {
+	var graphqlErrors []gqlerrors.GraphQLError
+	if queryResponse != nil {
+		for _, gqlErr := range queryResponse.Errors {
+			graphqlErrors = append(graphqlErrors, gqlerrors.GraphQLError{Message: gqlErr.Message, Extensions: gqlErr.Extensions})
+		}
+	}
+	if requestErr != nil {
+		graphqlErrors = append(graphqlErrors, requestErr.GraphQLErrors...)
+	}
+
+	for _, gqlErr := range graphqlErrors {
+		code, _ := gqlErr.Extensions["code"].(string)
+		switch {
+		case code == "PERSISTED_QUERY_NOT_FOUND" || gqlErr.Message == "PersistedQueryNotFound":
+			return "PERSISTED_QUERY_NOT_FOUND"
+		case code == "PERSISTED_QUERY_NOT_SUPPORTED" || gqlErr.Message == "PersistedQueryNotSupported":
+			return "PERSISTED_QUERY_NOT_SUPPORTED"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// persistedQueryRequest is the body sent for a persisted query.
+type persistedQueryRequest struct {
+	Query      string `json:"query"`
+	Extensions struct {
+		PersistedQuery struct {
+			Version    int    `json:"version"`
+			Sha256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// newAPQServer emulates a server supporting Automatic Persisted Queries.
+func newAPQServer(t *testing.T) (*httptest.Server, *[]persistedQueryRequest) {
+	var mu sync.Mutex
+	var requests []persistedQueryRequest
+	registered := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body persistedQueryRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, body)
+
+		hash := body.Extensions.PersistedQuery.Sha256Hash
+		if body.Query == "" {
+			if _, ok := registered[hash]; !ok {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+				return
+			}
+		} else {
+			sum := sha256.Sum256([]byte(body.Query))
+			assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+			registered[hash] = body.Query
+		}
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestPersistedQueries_Automatic(t *testing.T) {
+	server, requests := newAPQServer(t)
+
+	persisted, diags := newPersistedQueries(&PersistedQueriesModel{Mode: types.StringNull(), ManifestFile: types.StringNull()})
+	require.False(t, diags.HasError())
+	config := &graphqlProviderConfig{
+		GQLServerUrl:     server.URL,
+		RequestHeaders:   map[string]interface{}{},
+		PersistedQueries: persisted,
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { ok }", nil, config)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		assert.Equal(t, map[string]interface{}{"ok": true}, resp.Data)
+	}
+
+	// The first request registers the document, the second only sends the hash
+	require.Len(t, *requests, 3)
+	assert.Empty(t, (*requests)[0].Query)
+	assert.Equal(t, "query { ok }", (*requests)[1].Query)
+	assert.Empty(t, (*requests)[2].Query)
+	for _, request := range *requests {
+		assert.Equal(t, 1, request.Extensions.PersistedQuery.Version)
+	}
+}
+
+func TestPersistedQueries_NotSupported(t *testing.T) {
+	var requests []persistedQueryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body persistedQueryRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+		if body.Query == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotSupported"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer server.Close()
+
+	persisted, diags := newPersistedQueries(&PersistedQueriesModel{Mode: types.StringValue("automatic"), ManifestFile: types.StringNull()})
+	require.False(t, diags.HasError())
+	config := &graphqlProviderConfig{
+		GQLServerUrl:     server.URL,
+		RequestHeaders:   map[string]interface{}{},
+		PersistedQueries: persisted,
+	}
+
+	for i := 0; i < 2; i++ {
+		_, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { ok }", nil, config)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	}
+
+	// Once the server reports no support, documents are sent without a hash
+	require.Len(t, requests, 3)
+	assert.Empty(t, requests[0].Query)
+	assert.Empty(t, requests[1].Extensions.PersistedQuery.Sha256Hash)
+	assert.Empty(t, requests[2].Extensions.PersistedQuery.Sha256Hash)
+	assert.Equal(t, "query { ok }", requests[2].Query)
+}
+
+func TestPersistedQueries_Strict(t *testing.T) {
+	manifestFile := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(manifestFile, []byte(`{
+		"format": "apollo-persisted-query-manifest",
+		"version": 1,
+		"operations": [{"id": "op-1", "name": "Ok", "type": "query", "body": "query Ok {\n  ok\n}"}]
+	}`), 0o600))
+
+	var requests []persistedQueryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body persistedQueryRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer server.Close()
+
+	persisted, diags := newPersistedQueries(&PersistedQueriesModel{Mode: types.StringValue("strict"), ManifestFile: types.StringValue(manifestFile)})
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	config := &graphqlProviderConfig{
+		GQLServerUrl:     server.URL,
+		RequestHeaders:   map[string]interface{}{},
+		PersistedQueries: persisted,
+	}
+
+	_, _, _, diags = executeSingleGraphQLRequest(context.Background(), "query Ok { ok }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	require.Len(t, requests, 1)
+	assert.Empty(t, requests[0].Query)
+	assert.Equal(t, "op-1", requests[0].Extensions.PersistedQuery.Sha256Hash)
+
+	_, _, _, diags = executeSingleGraphQLRequest(context.Background(), "query Other { ok }", nil, config)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Persisted Query Not Found", diags[0].Summary())
+	assert.Len(t, requests, 1, "operations missing from the manifest are not sent")
+}
+
+func TestLoadPersistedQueryManifest_Flat(t *testing.T) {
+	manifestFile := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(manifestFile, []byte(`{"abc123": "mutation  Save {\n save }"}`), 0o600))
+
+	manifest, err := loadPersistedQueryManifest(manifestFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"mutation Save { save }": "abc123"}, manifest)
+
+	require.NoError(t, os.WriteFile(manifestFile, []byte(`[]`), 0o600))
+	_, err = loadPersistedQueryManifest(manifestFile)
+	assert.Error(t, err)
+}
+
+func TestValidatePersistedQueries(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     PersistedQueriesModel
+		expectErr bool
+	}{
+		{
+			name:  "automatic by default",
+			model: PersistedQueriesModel{Mode: types.StringNull(), ManifestFile: types.StringNull()},
+		},
+		{
+			name:  "strict with manifest",
+			model: PersistedQueriesModel{Mode: types.StringValue("strict"), ManifestFile: types.StringValue("manifest.json")},
+		},
+		{
+			name:      "strict without manifest",
+			model:     PersistedQueriesModel{Mode: types.StringValue("strict"), ManifestFile: types.StringNull()},
+			expectErr: true,
+		},
+		{
+			name:      "unknown mode",
+			model:     PersistedQueriesModel{Mode: types.StringValue("always"), ManifestFile: types.StringNull()},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validatePersistedQueries(&tt.model)
+			assert.Equal(t, tt.expectErr, diags.HasError())
+		})
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"os"
 	"strings"
 	"time"
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -37,19 +38,53 @@ type GraphqlProvider struct {
 
 // GraphqlProviderModel describes the provider data model
 type GraphqlProviderModel struct {
-	URL                            types.String `tfsdk:"url"`
-	Headers                        types.Map    `tfsdk:"headers"`
-	OAuth2LoginQuery               types.String `tfsdk:"oauth2_login_query"`
-	OAuth2LoginQueryVariables      types.Map    `tfsdk:"oauth2_login_query_variables"`
-	OAuth2LoginQueryValueAttribute types.String `tfsdk:"oauth2_login_query_value_attribute"`
+	URL                             types.String `tfsdk:"url"`
+	Headers                         types.Map    `tfsdk:"headers"`
+	OAuth2LoginQuery                types.String `tfsdk:"oauth2_login_query"`
+	OAuth2LoginQueryVariables       types.Map    `tfsdk:"oauth2_login_query_variables"`
+	OAuth2LoginQueryValueAttribute  types.String `tfsdk:"oauth2_login_query_value_attribute"`
+	OAuth2LoginQueryExpiryAttribute types.String `tfsdk:"oauth2_login_query_expiry_attribute"`
 	// REST OAuth2 support
 	OAuth2RestURL          types.String `tfsdk:"oauth2_rest_url"`
 	OAuth2RestMethod       types.String `tfsdk:"oauth2_rest_method"`
 	OAuth2RestHeaders      types.Map    `tfsdk:"oauth2_rest_headers"`
 	OAuth2RestBody         types.String `tfsdk:"oauth2_rest_body"`
 	OAuth2RestTokenPath    types.String `tfsdk:"oauth2_rest_token_path"`
+	OAuth2RestExpiryPath   types.String `tfsdk:"oauth2_rest_expiry_path"`
 	QueryRateLimitDelay    types.String `tfsdk:"query_rate_limit_delay"`
 	MutationRateLimitDelay types.String `tfsdk:"mutation_rate_limit_delay"`
+	RateLimitBurst         types.Int64  `tfsdk:"rate_limit_burst"`
+	AdaptiveRateLimit      types.Bool   `tfsdk:"adaptive_rate_limit"`
+	MaxConcurrentRequests  types.Int64  `tfsdk:"max_concurrent_requests"`
+	QueryMethod            types.String `tfsdk:"query_method"`
+	SensitiveVariablePaths types.List   `tfsdk:"sensitive_variable_paths"`
+	// File-based credentials support
+	TokenFile       types.String `tfsdk:"token_file"`
+	HeadersFromFile types.String `tfsdk:"headers_from_file"`
+	// TLS support
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	// Native OAuth2 client credentials support
+	OAuth2ClientCredentials *OAuth2ClientCredentialsModel `tfsdk:"oauth2_client_credentials"`
+	// AWS SigV4 request signing support
+	AWSSigV4 *AWSSigV4Model `tfsdk:"aws_sigv4"`
+	// External credential process support
+	CredentialProcess *CredentialProcessModel `tfsdk:"credential_process"`
+	// Cookie session login support
+	SessionLogin *SessionLoginModel `tfsdk:"session_login"`
+	// JWT assertion support for the REST OAuth2 flow
+	OAuth2JWTAssertion *OAuth2JWTAssertionModel `tfsdk:"oauth2_jwt_assertion"`
+	// HTTP transport settings
+	HTTP *HTTPModel `tfsdk:"http"`
+	// Query cost based throttling
+	CostThrottle *CostThrottleModel `tfsdk:"cost_throttle"`
+	// Persisted query support
+	PersistedQueries *PersistedQueriesModel `tfsdk:"persisted_queries"`
+	// Query batching support
+	Batching *BatchingModel `tfsdk:"batching"`
 }
 
 // Metadata returns the provider type name.
@@ -70,24 +105,28 @@ func (p *GraphqlProvider) Schema(ctx context.Context, req provider.SchemaRequest
 			"headers": providerschema.MapAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
-				Description: "Additional headers to send with requests.",
+				Description: "Additional headers to send with requests. Values may reference secrets as `$${env:NAME}` or `$${file:/path}`.",
 			},
 			"oauth2_login_query": providerschema.StringAttribute{
 				Optional:    true,
-				Description: "GraphQL query for OAuth2 login.",
+				Description: "GraphQL query for OAuth2 login. Cannot be combined with `oauth2_rest_url`.",
 			},
 			"oauth2_login_query_variables": providerschema.MapAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
-				Description: "Variables for the OAuth2 login query.",
+				Description: "Variables for the OAuth2 login query. Values may reference secrets as `$${env:NAME}` or `$${file:/path}`.",
 			},
 			"oauth2_login_query_value_attribute": providerschema.StringAttribute{
 				Optional:    true,
 				Description: "Attribute path to extract the token from the OAuth2 login response.",
 			},
+			"oauth2_login_query_expiry_attribute": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "Attribute path to extract the token expiry from the OAuth2 login response, either as seconds until expiry or an RFC 3339 timestamp. When set, the token is refreshed shortly before it expires.",
+			},
 			"oauth2_rest_url": providerschema.StringAttribute{
 				Optional:    true,
-				Description: "REST URL for OAuth2 token endpoint (alternative to GraphQL OAuth2).",
+				Description: "REST URL for OAuth2 token endpoint (alternative to GraphQL OAuth2). Cannot be combined with `oauth2_login_query`.",
 			},
 			"oauth2_rest_method": providerschema.StringAttribute{
 				Optional:    true,
@@ -96,16 +135,20 @@ func (p *GraphqlProvider) Schema(ctx context.Context, req provider.SchemaRequest
 			"oauth2_rest_headers": providerschema.MapAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
-				Description: "Headers for REST OAuth2 request.",
+				Description: "Headers for REST OAuth2 request. Values may reference secrets as `$${env:NAME}` or `$${file:/path}`.",
 			},
 			"oauth2_rest_body": providerschema.StringAttribute{
 				Optional:    true,
-				Description: "Request body for REST OAuth2 request (e.g., form-encoded or JSON). Supports environment variable substitution: use ${var.wiz_client_id} or $wiz_client_id to reference WIZ_CLIENT_ID environment variable, and ${var.wiz_client_secret} or $wiz_client_secret for WIZ_CLIENT_SECRET.",
+				Description: "Request body for REST OAuth2 request (e.g., form-encoded or JSON). Secrets may be referenced as `$${env:NAME}` or `$${file:/path}`. The legacy $wiz_client_id and $wiz_client_secret placeholders are still substituted from WIZ_CLIENT_ID and WIZ_CLIENT_SECRET.",
 			},
 			"oauth2_rest_token_path": providerschema.StringAttribute{
 				Optional:    true,
 				Description: "JSON path to extract token from REST OAuth2 response (e.g., 'access_token').",
 			},
+			"oauth2_rest_expiry_path": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "JSON path to extract the token expiry (seconds until expiry or an RFC 3339 timestamp) from the REST OAuth2 response. Default: 'expires_in'.",
+			},
 			"query_rate_limit_delay": providerschema.StringAttribute{
 				Optional:    true,
 				Description: "Delay between query requests (e.g., '100ms'). Default: 100ms for queries (10/sec).",
@@ -114,6 +157,329 @@ func (p *GraphqlProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				Optional:    true,
 				Description: "Delay between mutation requests (e.g., '400ms'). Default: 400ms for mutations (3/sec).",
 			},
+			"rate_limit_burst": providerschema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of queries or mutations that may be sent back to back before the rate limit delays apply. Default: 1.",
+			},
+			"adaptive_rate_limit": providerschema.BoolAttribute{
+				Optional:    true,
+				Description: "Slow down as the `X-RateLimit-Remaining` (or `RateLimit-Remaining`) response header approaches zero, and pause until the limit resets once it is exhausted. Default: false.",
+			},
+			"max_concurrent_requests": providerschema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of GraphQL requests in flight at once, regardless of Terraform's parallelism. Default: unlimited.",
+			},
+			"query_method": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "HTTP method for queries sent by the `graphql_query` data source and `read_query`: `POST` (default) or `GET`. GET requests encode the query, variables and operation name in the URL so HTTP caches apply, and responses are revalidated with `If-None-Match` when the server sends an `ETag`. Mutations are always sent with POST.",
+			},
+			"sensitive_variable_paths": providerschema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Dot-separated paths of GraphQL variables whose values are redacted from debug logs (e.g., `input.password`). A `*` segment matches any key and lists are traversed automatically. Authorization, cookie and API key headers are always redacted.",
+			},
+			"token_file": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a file containing a bearer token, sent as the `Authorization` header. The file is re-read whenever it changes, so tokens rotated by an external agent are picked up without re-running Terraform. Alternative to the other authentication options.",
+			},
+			"headers_from_file": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a file containing a JSON object of additional headers. The file is re-read whenever it changes and its values take precedence over `headers`.",
+			},
+			"ca_cert_pem": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "PEM-encoded CA certificates trusted in addition to the system roots for the GraphQL and token endpoints. Conflicts with `ca_cert_file`.",
+			},
+			"ca_cert_file": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a PEM-encoded CA bundle trusted in addition to the system roots for the GraphQL and token endpoints. Conflicts with `ca_cert_pem`.",
+			},
+			"client_cert": providerschema.StringAttribute{
+				Optional:    true,
+				Description: "PEM-encoded client certificate presented for mutual TLS. May reference `$${file:/path}`. Requires `client_key`.",
+			},
+			"client_key": providerschema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "PEM-encoded private key for `client_cert`. May reference `$${env:NAME}` or `$${file:/path}`.",
+			},
+			"insecure_skip_verify": providerschema.BoolAttribute{
+				Optional:    true,
+				Description: "Disable TLS certificate verification for the GraphQL and token endpoints. Insecure; only use for local development.",
+			},
+		},
+		Blocks: map[string]providerschema.Block{
+			"aws_sigv4": providerschema.SingleNestedBlock{
+				Description: "Sign every GraphQL request with AWS Signature Version 4 (e.g., for AWS AppSync IAM authorization). Cannot be combined with the OAuth2 options.",
+				Attributes: map[string]providerschema.Attribute{
+					"region": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "AWS region of the endpoint. Defaults to AWS_REGION, AWS_DEFAULT_REGION or the region of the profile in the shared config file.",
+					},
+					"service": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Signing name of the AWS service. Default: `appsync`.",
+					},
+					"access_key": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "AWS access key ID. When unset, AWS_ACCESS_KEY_ID or the shared credentials file is used.",
+					},
+					"secret_key": providerschema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "AWS secret access key. Required when `access_key` is set.",
+					},
+					"session_token": providerschema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "AWS session token for temporary credentials.",
+					},
+					"profile": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Profile to read from the shared credentials and config files. Its static keys are re-read when the files change, and a `credential_process` in the config file is run again shortly before the credentials it returns expire. Defaults to AWS_PROFILE or `default`.",
+					},
+				},
+			},
+			"credential_process": providerschema.SingleNestedBlock{
+				Description: "Obtain a bearer token by running an external command, similar to AWS `credential_process` and kubectl exec plugins. " +
+					"The command must print a JSON object with a `token` (or `access_token`, or kubectl's `status.token`) and optionally an expiry: `expires_at`, `expiration`, `Expiration` or `status.expirationTimestamp` as a Unix or RFC 3339 timestamp, or `expires_in` as seconds from now. " +
+					"It is run again when the token expires or is rejected. Alternative to the OAuth2 options.",
+				Attributes: map[string]providerschema.Attribute{
+					"command": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Command to execute. Required when the block is set.",
+					},
+					"args": providerschema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "Arguments passed to the command.",
+					},
+				},
+			},
+			"oauth2_jwt_assertion": providerschema.SingleNestedBlock{
+				Description: "Sign a JWT assertion (RFC 7523) with a private key and send it to `oauth2_rest_url`, instead of a shared client secret. Any form parameters in `oauth2_rest_body` (e.g., `scope`) are sent along with the assertion.",
+				Attributes: map[string]providerschema.Attribute{
+					"private_key": providerschema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "PEM-encoded RSA or P-256 ECDSA private key (PKCS#8, PKCS#1 or SEC 1). May reference `$${env:NAME}` or `$${file:/path}`. Required when the block is set.",
+					},
+					"algorithm": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "JWS signing algorithm: `RS256` (default) or `ES256`.",
+					},
+					"key_id": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Key ID sent as the `kid` header so the server can select the verification key.",
+					},
+					"mode": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "`private_key_jwt` (default) sends the JWT as `client_assertion` to authenticate the client; `jwt_bearer` sends it as the `assertion` of a JWT bearer grant.",
+					},
+					"issuer": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "The `iss` claim, usually the client ID. Required when the block is set.",
+					},
+					"subject": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "The `sub` claim. Defaults to `issuer`.",
+					},
+					"audience": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "The `aud` claim. Defaults to `oauth2_rest_url`.",
+					},
+					"lifetime": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "How long the assertion is valid (e.g., '5m'). Default: 5m.",
+					},
+					"claims": providerschema.MapAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "Additional claims to include in the assertion.",
+					},
+				},
+			},
+			"session_login": providerschema.SingleNestedBlock{
+				Description: "Authenticate with a session cookie set by a login mutation (e.g., Django/Graphene or Rails). Cookies are kept for all subsequent requests and, when configured, a CSRF token is sent with every request. " +
+					"The login is repeated when a request is rejected as unauthenticated, i.e. with HTTP 401 or a GraphQL error whose `extensions.code` is `UNAUTHENTICATED`, `UNAUTHORIZED`, `INVALID_TOKEN` or `TOKEN_EXPIRED`. Alternative to the token-based authentication options.",
+				Attributes: map[string]providerschema.Attribute{
+					"query": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "GraphQL login mutation that sets the session cookie. Required when the block is set.",
+					},
+					"variables": providerschema.MapAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "Variables for the login mutation. Values may reference secrets as `$${env:NAME}` or `$${file:/path}`.",
+					},
+					"csrf_url": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "URL requested with GET before logging in to obtain a CSRF token, either as the `csrf_cookie_name` cookie or at `csrf_token_path` in a JSON response.",
+					},
+					"csrf_cookie_name": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Name of the cookie holding the CSRF token (e.g., `csrftoken` for Django). The cookie is re-read after login as servers often rotate it.",
+					},
+					"csrf_token_path": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "JSON path to the CSRF token in the `csrf_url` response. Requires `csrf_url`.",
+					},
+					"csrf_header_name": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Header the CSRF token is sent in. Default: `X-CSRFToken`.",
+					},
+				},
+			},
+			"cost_throttle": providerschema.SingleNestedBlock{
+				Description: "Pace requests to stay within a query cost budget reported in responses, such as Shopify's `extensions.cost` or GitHub's `rateLimit` object. " +
+					"The cost of the previous request is used as the estimate for the next one. Paths use GJSON syntax and are evaluated against the whole response body.",
+				Attributes: map[string]providerschema.Attribute{
+					"remaining_path": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Path to the remaining budget (e.g., `extensions.cost.throttleStatus.currentlyAvailable` or `data.rateLimit.remaining`). Required when the block is set.",
+					},
+					"cost_path": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Path to the cost of the request (e.g., `extensions.cost.requestedQueryCost` or `data.rateLimit.cost`).",
+					},
+					"maximum_path": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Path to the maximum budget (e.g., `extensions.cost.throttleStatus.maximumAvailable`).",
+					},
+					"restore_rate_path": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Path to the number of points restored per second (e.g., `extensions.cost.throttleStatus.restoreRate`).",
+					},
+					"restore_rate": providerschema.Float64Attribute{
+						Optional:    true,
+						Description: "Points restored per second, for APIs that do not report it.",
+					},
+					"reset_at_path": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Path to the time the budget is reset, as an RFC 3339 timestamp or Unix seconds (e.g., `data.rateLimit.resetAt`). Used when there is no restore rate.",
+					},
+					"min_remaining": providerschema.Float64Attribute{
+						Optional:    true,
+						Description: "Budget to keep in reserve for other clients. Default: 0.",
+					},
+				},
+			},
+			"batching": providerschema.SingleNestedBlock{
+				Description: "Send queries issued at about the same time, such as the `read_query` of many `graphql_mutation` resources during a refresh, as a single array-batched request (supported by e.g. Apollo Server, Hasura and graphql-java). The rate limits apply to each batch rather than to each query. Mutations, persisted queries, file uploads and queries sent with GET are never batched. If the server does not respond to a batch with an array of results, queries are sent individually from then on.",
+				Attributes: map[string]providerschema.Attribute{
+					"mode": providerschema.StringAttribute{
+						Optional: true,
+						Description: "`array` (default) sends the queries of a batch as a JSON array. " +
+							"`alias` is for servers without array batching: queries with the same document, such as the `read_query` of `graphql_mutation` resources, are merged into one query with aliased top-level fields and renamed variables, and the response is split back up. Documents with top-level fragment spreads, operation directives or fragments that use variables are sent individually.",
+					},
+					"window": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "How long to wait for more queries before sending a batch. Default: 10ms.",
+					},
+					"max_size": providerschema.Int64Attribute{
+						Optional:    true,
+						Description: "Maximum number of queries in a batch; a full batch is sent right away. Default: 10.",
+					},
+				},
+			},
+			"persisted_queries": providerschema.SingleNestedBlock{
+				Description: "Send queries and mutations as persisted queries, identified by their SHA-256 hash instead of the full document. Applies to the `graphql_query` data source and all `graphql_mutation` operations.",
+				Attributes: map[string]providerschema.Attribute{
+					"mode": providerschema.StringAttribute{
+						Optional: true,
+						Description: "`automatic` (default) uses Automatic Persisted Queries: the hash is sent first and the full document only when the server reports `PersistedQueryNotFound`. " +
+							"`strict` only sends hashes from `manifest_file`, for servers that allow persisted operations only.",
+					},
+					"manifest_file": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Path to a persisted query manifest, either in the Apollo format or as a JSON object of IDs to documents. Operations are matched ignoring whitespace and sent with their manifest ID. Required when `mode` is `strict`.",
+					},
+				},
+			},
+			"http": providerschema.SingleNestedBlock{
+				Description: "HTTP transport settings. Connections are pooled and reused for the GraphQL and token endpoints. Responses are requested with gzip, br or zstd compression and may be gzip, deflate, br or zstd encoded; responses re-encoded with anything else (e.g. by a proxy) fail with an error.",
+				Attributes: map[string]providerschema.Attribute{
+					"request_timeout": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Overall timeout for each request, including reading the response (e.g., '30s'). Default: 30s.",
+					},
+					"dial_timeout": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Timeout for establishing a TCP connection. Default: 30s.",
+					},
+					"tls_handshake_timeout": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Timeout for the TLS handshake. Default: 10s.",
+					},
+					"keep_alive": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Interval between TCP keep-alive probes on open connections. Default: 30s.",
+					},
+					"idle_conn_timeout": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "How long an idle connection is kept open for reuse. Default: 90s.",
+					},
+					"max_idle_conns_per_host": providerschema.Int64Attribute{
+						Optional:    true,
+						Description: "Maximum number of idle connections kept open per host. Default: 10.",
+					},
+					"max_retries": providerschema.Int64Attribute{
+						Optional:    true,
+						Description: "Maximum number of times a request is retried after a rate limit, a 502, 503 or 504 response, or a transient network error. Mutations are only retried when they cannot have reached the server: after a rate limit, a 503 with Retry-After, or a failure to connect. Default: 5.",
+					},
+					"retry_backoff_base": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Delay before the first retry, doubled with every attempt and jittered. A delay requested by the server with `Retry-After` or a rate limit reset header is used instead. Default: 1s.",
+					},
+					"retry_backoff_max": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Maximum delay between retries. Default: 30s.",
+					},
+					"proxy_url": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Proxy for all requests (e.g., 'http://proxy:3128'). When not set, the `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables are used.",
+					},
+					"compress_requests": providerschema.BoolAttribute{
+						Optional:    true,
+						Description: "Compress GraphQL request bodies of 1 KiB or more with gzip (`Content-Encoding: gzip`). The server must support compressed requests. Default: false.",
+					},
+					"max_response_size": providerschema.Int64Attribute{
+						Optional:    true,
+						Description: "Maximum size in bytes of a GraphQL, token endpoint or CSRF response body after decompression. Larger responses fail with an error instead of being read into memory. Default: 67108864 (64 MiB).",
+					},
+				},
+			},
+			"oauth2_client_credentials": providerschema.SingleNestedBlock{
+				Description: "Obtain a bearer token using the OAuth2 client credentials grant (RFC 6749 section 4.4). Alternative to `oauth2_login_query` and `oauth2_rest_url`.",
+				Attributes: map[string]providerschema.Attribute{
+					"token_url": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "URL of the OAuth2 token endpoint. Required when the block is set.",
+					},
+					"client_id": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "OAuth2 client identifier. Required when the block is set. May reference `$${env:NAME}` or `$${file:/path}`.",
+					},
+					"client_secret": providerschema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "OAuth2 client secret. Required when the block is set. May reference `$${env:NAME}` or `$${file:/path}`.",
+					},
+					"scopes": providerschema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "Scopes to request. Sent as a space-delimited `scope` parameter.",
+					},
+					"audience": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "Optional `audience` parameter for token endpoints that require one (e.g., Auth0).",
+					},
+					"auth_style": providerschema.StringAttribute{
+						Optional:    true,
+						Description: "How client credentials are sent to the token endpoint: `header` (HTTP Basic, default) or `body` (form parameters).",
+					},
+				},
+			},
 		},
 	}
 }
@@ -145,57 +511,81 @@ func (p *GraphqlProvider) Configure(ctx context.Context, req provider.ConfigureR
 		RequestHeaders: make(map[string]interface{}),
 	}
 
-	// Convert headers from types.Map to map[string]interface{}
+	// Build the HTTP client shared by GraphQL requests and token endpoints
+	httpClient, diags := buildHTTPClient(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.HTTPClient = httpClient
+
+	retry, diags := buildRetryPolicy(data.HTTP)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.Retry = &retry
+
+	body, diags := buildBodySettings(data.HTTP)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.Body = &body
+
+	// Session logins keep their cookies on the shared client
+	if data.SessionLogin != nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			resp.Diagnostics.AddError("Cookie Jar Error", fmt.Sprintf("failed to create cookie jar: %v", err))
+			return
+		}
+		config.HTTPClient.Jar = jar
+	}
+
+	// Convert headers from types.Map to map[string]interface{}, resolving
+	// ${env:NAME} and ${file:/path} references
 	if !data.Headers.IsNull() && !data.Headers.IsUnknown() {
-		elements := make(map[string]types.String)
+		elements := make(map[string]string)
 		resp.Diagnostics.Append(data.Headers.ElementsAs(ctx, &elements, false)...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-		for k, v := range elements {
-			config.RequestHeaders[k] = v.ValueString()
+		headers, diags := resolveSecretReferencesInMap(path.Root("headers"), elements)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for k, v := range headers {
+			config.RequestHeaders[k] = v
 		}
 	}
 
-	// Handle OAuth2 configuration
-	if !data.OAuth2LoginQuery.IsNull() && !data.OAuth2LoginQuery.IsUnknown() {
-		if data.OAuth2LoginQueryVariables.IsNull() || data.OAuth2LoginQueryVariables.IsUnknown() ||
-			data.OAuth2LoginQueryValueAttribute.IsNull() || data.OAuth2LoginQueryValueAttribute.IsUnknown() {
-			resp.Diagnostics.AddError(
-				"Incomplete OAuth 2.0 provider configuration",
-				"All three attributes must be set: `oauth2_login_query`, `oauth2_login_query_variables` and `oauth2_login_query_value_attribute`.",
-			)
+	// Handle variable paths that must not appear in logs
+	if !data.SensitiveVariablePaths.IsNull() && !data.SensitiveVariablePaths.IsUnknown() {
+		var paths []string
+		resp.Diagnostics.Append(data.SensitiveVariablePaths.ElementsAs(ctx, &paths, false)...)
+		if resp.Diagnostics.HasError() {
 			return
 		}
+		config.SensitiveVariablePaths = parseSensitiveVariablePaths(paths)
+	}
 
-		// Perform GraphQL OAuth2 login
-		token, diags := p.performOAuth2Login(ctx, config, data)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
-			return
-		}
-		config.RequestAuthorizationHeaders = map[string]interface{}{
-			"Authorization": "Bearer " + token,
-		}
-	} else if !data.OAuth2RestURL.IsNull() && !data.OAuth2RestURL.IsUnknown() {
-		// Handle REST OAuth2 configuration
-		if data.OAuth2RestTokenPath.IsNull() || data.OAuth2RestTokenPath.IsUnknown() {
-			resp.Diagnostics.AddError(
-				"Incomplete REST OAuth2 configuration",
-				"Both `oauth2_rest_url` and `oauth2_rest_token_path` must be set for REST OAuth2.",
-			)
+	// Handle file-based token and headers, which are re-read on every request
+	if isSet(data.TokenFile) {
+		config.TokenFile = newCachedFile(data.TokenFile.ValueString())
+		if _, err := config.TokenFile.ReadToken(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("token_file"), "Unable to Read Token File", err.Error())
 			return
 		}
+	}
 
-		// Perform REST OAuth2 login
-		token, diags := p.performRestOAuth2Login(ctx, data)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
+	if isSet(data.HeadersFromFile) {
+		config.HeadersFile = newCachedFile(data.HeadersFromFile.ValueString())
+		if _, err := config.HeadersFile.ReadHeaders(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("headers_from_file"), "Unable to Read Headers File", err.Error())
 			return
 		}
-		config.RequestAuthorizationHeaders = map[string]interface{}{
-			"Authorization": "Bearer " + token,
-		}
 	}
 
 	// Handle rate limit delay
@@ -223,6 +613,188 @@ func (p *GraphqlProvider) Configure(ctx context.Context, req provider.ConfigureR
 		config.MutationRateLimitDelay = 400 * time.Millisecond
 	}
 
+	burst := 1
+	if !data.RateLimitBurst.IsNull() && !data.RateLimitBurst.IsUnknown() {
+		if data.RateLimitBurst.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("rate_limit_burst"), "Invalid Rate Limit Burst", "`rate_limit_burst` must be at least 1.")
+			return
+		}
+		burst = int(data.RateLimitBurst.ValueInt64())
+	}
+	adaptive := !data.AdaptiveRateLimit.IsNull() && !data.AdaptiveRateLimit.IsUnknown() && data.AdaptiveRateLimit.ValueBool()
+	config.initializeRateLimiters(burst, adaptive)
+
+	if !data.MaxConcurrentRequests.IsNull() && !data.MaxConcurrentRequests.IsUnknown() {
+		if data.MaxConcurrentRequests.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_concurrent_requests"), "Invalid Maximum Concurrent Requests", "`max_concurrent_requests` must be at least 1.")
+			return
+		}
+		config.RequestSlots = newRequestSemaphore(int(data.MaxConcurrentRequests.ValueInt64()))
+	}
+
+	if !data.QueryMethod.IsNull() && !data.QueryMethod.IsUnknown() {
+		method, err := validateQueryMethod(data.QueryMethod.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("query_method"), "Invalid Query Method", err.Error())
+			return
+		}
+		config.QueryMethod = method
+		if method == http.MethodGet {
+			config.ETags = newETagCache()
+		}
+	}
+
+	if data.CostThrottle != nil {
+		resp.Diagnostics.Append(validateCostThrottle(data.CostThrottle)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config.CostThrottle = newCostThrottle(data.CostThrottle)
+	}
+
+	if data.PersistedQueries != nil {
+		resp.Diagnostics.Append(validatePersistedQueries(data.PersistedQueries)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		persisted, diags := newPersistedQueries(data.PersistedQueries)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config.PersistedQueries = persisted
+	}
+
+	if data.Batching != nil {
+		batcher, diags := buildQueryBatcher(data.Batching)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config.Batcher = batcher
+	}
+
+	if data.OAuth2JWTAssertion != nil && (data.OAuth2RestURL.IsNull() || data.OAuth2RestURL.IsUnknown()) {
+		resp.Diagnostics.AddError(
+			"Incomplete OAuth2 JWT assertion configuration",
+			"`oauth2_jwt_assertion` requires `oauth2_rest_url` to be set.",
+		)
+		return
+	}
+
+	if modes := authenticationModes(data); len(modes) > 1 {
+		resp.Diagnostics.AddError(
+			"Conflicting authentication configuration",
+			fmt.Sprintf("Only one authentication option may be configured, but found %s.", strings.Join(modes, ", ")),
+		)
+		return
+	}
+
+	if data.AWSSigV4 != nil {
+		signer, diags := newAWSSigV4Signer(ctx, data.AWSSigV4)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config.Signer = signer
+	}
+
+	// Handle OAuth2 configuration. Each flow is wrapped in a token source so
+	// the provider can log in again when the token expires or is rejected.
+	var fetchToken tokenFetchFunc
+	if !data.OAuth2LoginQuery.IsNull() && !data.OAuth2LoginQuery.IsUnknown() {
+		if data.OAuth2LoginQueryVariables.IsNull() || data.OAuth2LoginQueryVariables.IsUnknown() ||
+			data.OAuth2LoginQueryValueAttribute.IsNull() || data.OAuth2LoginQueryValueAttribute.IsUnknown() {
+			resp.Diagnostics.AddError(
+				"Incomplete OAuth 2.0 provider configuration",
+				"All three attributes must be set: `oauth2_login_query`, `oauth2_login_query_variables` and `oauth2_login_query_value_attribute`.",
+			)
+			return
+		}
+
+		// The login query itself must be sent without the token it produces
+		loginConfig := config.withoutAuthentication()
+		// Login variables are credentials, so none of them are logged
+		loginConfig.SensitiveVariablePaths = [][]string{{"*"}}
+		fetchToken = func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			return p.performOAuth2Login(ctx, loginConfig, data)
+		}
+	} else if !data.OAuth2RestURL.IsNull() && !data.OAuth2RestURL.IsUnknown() {
+		// Handle REST OAuth2 configuration
+		if data.OAuth2RestTokenPath.IsNull() || data.OAuth2RestTokenPath.IsUnknown() {
+			resp.Diagnostics.AddError(
+				"Incomplete REST OAuth2 configuration",
+				"Both `oauth2_rest_url` and `oauth2_rest_token_path` must be set for REST OAuth2.",
+			)
+			return
+		}
+
+		if data.OAuth2JWTAssertion != nil {
+			resp.Diagnostics.Append(validateJWTAssertion(data.OAuth2JWTAssertion)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
+		fetchToken = func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			return p.performRestOAuth2Login(ctx, config, data)
+		}
+	} else if data.OAuth2ClientCredentials != nil {
+		// Handle native OAuth2 client credentials configuration
+		resp.Diagnostics.Append(validateClientCredentials(data.OAuth2ClientCredentials)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		clientCredentials := data.OAuth2ClientCredentials
+		fetchToken = func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			return p.performClientCredentialsLogin(ctx, config, clientCredentials)
+		}
+	} else if data.CredentialProcess != nil {
+		// Handle external credential process configuration
+		resp.Diagnostics.Append(validateCredentialProcess(data.CredentialProcess)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		credentialProcess := data.CredentialProcess
+		fetchToken = func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			return p.runCredentialProcess(ctx, credentialProcess)
+		}
+	}
+
+	if data.SessionLogin != nil {
+		// Handle cookie session login configuration
+		resp.Diagnostics.Append(validateSessionLogin(data.SessionLogin)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		sessionLogin := data.SessionLogin
+		config.CSRFHeaderName = sessionLogin.csrfHeaderName()
+		config.SessionSource = newTokenSource(func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			return p.performSessionLogin(ctx, config, sessionLogin)
+		})
+
+		// Log in once up front so configuration errors surface immediately
+		_, diags := config.SessionSource.Token(ctx)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			return
+		}
+	}
+
+	if fetchToken != nil {
+		config.TokenSource = newTokenSource(fetchToken)
+
+		// Log in once up front so configuration errors surface immediately
+		_, diags := config.TokenSource.Token(ctx)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			return
+		}
+	}
+
 	// Make the GraphQL client available during DataSource and Resource
 	// type Configure methods.
 	resp.DataSourceData = config
@@ -231,8 +803,42 @@ func (p *GraphqlProvider) Configure(ctx context.Context, req provider.ConfigureR
 	tflog.Info(ctx, "Configured GraphQL client", map[string]any{"success": true})
 }
 
-// performOAuth2Login performs OAuth2 login and returns the access token.
-func (p *GraphqlProvider) performOAuth2Login(ctx context.Context, config *graphqlProviderConfig, data GraphqlProviderModel) (string, diag.Diagnostics) {
+// authenticationModes lists the mutually exclusive authentication options
+// set in the configuration.
+func authenticationModes(data GraphqlProviderModel) []string {
+	var modes []string
+	if !data.OAuth2LoginQuery.IsNull() && !data.OAuth2LoginQuery.IsUnknown() {
+		modes = append(modes, "`oauth2_login_query`")
+	}
+	// oauth2_jwt_assertion authenticates to oauth2_rest_url, so together they
+	// are one option
+	if !data.OAuth2RestURL.IsNull() && !data.OAuth2RestURL.IsUnknown() {
+		if data.OAuth2JWTAssertion != nil {
+			modes = append(modes, "`oauth2_rest_url` with `oauth2_jwt_assertion`")
+		} else {
+			modes = append(modes, "`oauth2_rest_url`")
+		}
+	}
+	if data.OAuth2ClientCredentials != nil {
+		modes = append(modes, "`oauth2_client_credentials`")
+	}
+	if data.CredentialProcess != nil {
+		modes = append(modes, "`credential_process`")
+	}
+	if isSet(data.TokenFile) {
+		modes = append(modes, "`token_file`")
+	}
+	if data.AWSSigV4 != nil {
+		modes = append(modes, "`aws_sigv4`")
+	}
+	if data.SessionLogin != nil {
+		modes = append(modes, "`session_login`")
+	}
+	return modes
+}
+
+// performOAuth2Login performs OAuth2 login and returns the access token and its expiry.
+func (p *GraphqlProvider) performOAuth2Login(ctx context.Context, config *graphqlProviderConfig, data GraphqlProviderModel) (string, time.Time, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	tflog.Debug(ctx, "Performing OAuth2 login")
@@ -240,21 +846,27 @@ func (p *GraphqlProvider) performOAuth2Login(ctx context.Context, config *graphq
 	// Convert variables to JSON string
 	var variablesJSON string
 	if !data.OAuth2LoginQueryVariables.IsNull() && !data.OAuth2LoginQueryVariables.IsUnknown() {
-		elements := make(map[string]types.String)
+		elements := make(map[string]string)
 		diags.Append(data.OAuth2LoginQueryVariables.ElementsAs(ctx, &elements, false)...)
 		if diags.HasError() {
-			return "", diags
+			return "", time.Time{}, diags
+		}
+
+		resolved, resolveDiags := resolveSecretReferencesInMap(path.Root("oauth2_login_query_variables"), elements)
+		diags.Append(resolveDiags...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
 		}
 
 		variablesMap := make(map[string]interface{})
-		for k, v := range elements {
-			variablesMap[k] = v.ValueString()
+		for k, v := range resolved {
+			variablesMap[k] = v
 		}
 
 		variablesBytes, err := json.Marshal(variablesMap)
 		if err != nil {
 			diags.AddError("OAuth2 Variable Marshaling Error", fmt.Sprintf("failed to marshal oauth2_login_query_variables: %v", err))
-			return "", diags
+			return "", time.Time{}, diags
 		}
 		variablesJSON = string(variablesBytes)
 	}
@@ -262,38 +874,50 @@ func (p *GraphqlProvider) performOAuth2Login(ctx context.Context, config *graphq
 	// Execute login query
 	queryResponse, resBytes, diags := queryExecuteFramework(ctx, config, data.OAuth2LoginQuery.ValueString(), variablesJSON, false)
 	if diags.HasError() {
-		return "", diags
+		return "", time.Time{}, diags
 	}
 
 	if len(queryResponse.Errors) > 0 {
 		for _, gqlErr := range queryResponse.Errors {
 			diags.AddError("OAuth2 Login Error", gqlErr.Message)
 		}
-		return "", diags
+		return "", time.Time{}, diags
 	}
 
-	// Extract token
-	tokenMap, err := computeMutationVariableKeys(
-		map[string]interface{}{"token": data.OAuth2LoginQueryValueAttribute.ValueString()},
-		string(resBytes),
-	)
+	// Extract token, and its expiry when the response exposes one
+	keys := map[string]interface{}{"token": data.OAuth2LoginQueryValueAttribute.ValueString()}
+	if !data.OAuth2LoginQueryExpiryAttribute.IsNull() && !data.OAuth2LoginQueryExpiryAttribute.IsUnknown() {
+		keys["expiry"] = data.OAuth2LoginQueryExpiryAttribute.ValueString()
+	}
+	tokenMap, err := computeMutationVariableKeys(ctx, keys, string(resBytes))
 	if err != nil {
 		diags.AddError("OAuth2 Token Extraction Error", err.Error())
-		return "", diags
+		return "", time.Time{}, diags
 	}
 
 	token, ok := tokenMap["token"]
 	if !ok {
 		diags.AddError("OAuth2 Token Not Found", "Could not extract token from response using the provided attribute path.")
-		return "", diags
+		return "", time.Time{}, diags
+	}
+
+	var expiry time.Time
+	if expiresIn, ok := tokenMap["expiry"]; ok {
+		expiry, err = parseTokenExpiry(expiresIn, time.Now())
+		if err != nil {
+			diags.AddError("OAuth2 Token Expiry Error", err.Error())
+			return "", time.Time{}, diags
+		}
 	}
 
-	tflog.Debug(ctx, "OAuth2 login successful")
-	return token, diags
+	tflog.Debug(ctx, "OAuth2 login successful", map[string]any{
+		"expiry": expiry,
+	})
+	return token, expiry, diags
 }
 
-// performRestOAuth2Login performs REST OAuth2 login and returns the access token.
-func (p *GraphqlProvider) performRestOAuth2Login(ctx context.Context, data GraphqlProviderModel) (string, diag.Diagnostics) {
+// performRestOAuth2Login performs REST OAuth2 login and returns the access token and its expiry.
+func (p *GraphqlProvider) performRestOAuth2Login(ctx context.Context, config *graphqlProviderConfig, data GraphqlProviderModel) (string, time.Time, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	tflog.Debug(ctx, "Performing REST OAuth2 login")
@@ -304,10 +928,14 @@ func (p *GraphqlProvider) performRestOAuth2Login(ctx context.Context, data Graph
 		method = data.OAuth2RestMethod.ValueString()
 	}
 
-	// Get request body, potentially enhanced with environment variables
-	body := data.OAuth2RestBody.ValueString()
+	// Get request body, resolving ${env:NAME} and ${file:/path} references
+	body, resolveDiags := resolveSecretReferences(path.Root("oauth2_rest_body"), data.OAuth2RestBody.ValueString())
+	diags.Append(resolveDiags...)
+	if diags.HasError() {
+		return "", time.Time{}, diags
+	}
 
-	// Check for environment variables and enhance the body if needed
+	// Legacy Wiz-specific environment variable substitution
 	if strings.Contains(body, "${var.wiz_client_id}") || strings.Contains(body, "$wiz_client_id") {
 		if envClientId := os.Getenv("WIZ_CLIENT_ID"); envClientId != "" {
 			body = strings.ReplaceAll(body, "${var.wiz_client_id}", envClientId)
@@ -328,11 +956,20 @@ func (p *GraphqlProvider) performRestOAuth2Login(ctx context.Context, data Graph
 		}
 	}
 
+	// Add a signed JWT assertion in place of a client secret
+	if data.OAuth2JWTAssertion != nil {
+		body, resolveDiags = buildJWTAssertionBody(ctx, data.OAuth2JWTAssertion, data.OAuth2RestURL.ValueString(), body)
+		diags.Append(resolveDiags...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
+		}
+	}
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, method, data.OAuth2RestURL.ValueString(), strings.NewReader(body))
 	if err != nil {
 		diags.AddError("REST OAuth2 Request Creation Error", fmt.Sprintf("failed to create request: %v", err))
-		return "", diags
+		return "", time.Time{}, diags
 	}
 
 	// Set default headers if not provided
@@ -341,52 +978,72 @@ func (p *GraphqlProvider) performRestOAuth2Login(ctx context.Context, data Graph
 		req.Header.Set("Accept", "application/json")
 	} else {
 		// Set custom headers
-		elements := make(map[string]types.String)
+		elements := make(map[string]string)
 		diags.Append(data.OAuth2RestHeaders.ElementsAs(ctx, &elements, false)...)
 		if diags.HasError() {
-			return "", diags
+			return "", time.Time{}, diags
+		}
+		headers, resolveDiags := resolveSecretReferencesInMap(path.Root("oauth2_rest_headers"), elements)
+		diags.Append(resolveDiags...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
 		}
-		for k, v := range elements {
-			req.Header.Set(k, v.ValueString())
+		for k, v := range headers {
+			req.Header.Set(k, v)
 		}
 	}
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := config.httpClient().Do(req)
 	if err != nil {
 		diags.AddError("REST OAuth2 Request Error", fmt.Sprintf("failed to execute request: %v", err))
-		return "", diags
+		return "", time.Time{}, diags
 	}
 	defer resp.Body.Close()
 
 	// Read response
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		diags.AddError("REST OAuth2 Response Error", fmt.Sprintf("failed to read response: %v", err))
-		return "", diags
+	bodyBytes, readDiags := config.readResponse(resp, data.OAuth2RestURL.ValueString())
+	diags.Append(readDiags...)
+	if diags.HasError() {
+		return "", time.Time{}, diags
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		diags.AddError("REST OAuth2 HTTP Error", fmt.Sprintf("received HTTP %d: %s", resp.StatusCode, string(bodyBytes)))
-		return "", diags
+		diags.AddError("REST OAuth2 HTTP Error", describeTokenError(resp.StatusCode, bodyBytes))
+		return "", time.Time{}, diags
 	}
 
 	// Extract token using JSON path
 	result := gjson.Get(string(bodyBytes), data.OAuth2RestTokenPath.ValueString())
 	if !result.Exists() {
 		diags.AddError("REST OAuth2 Token Extraction Error", fmt.Sprintf("token path '%s' not found in response", data.OAuth2RestTokenPath.ValueString()))
-		return "", diags
+		return "", time.Time{}, diags
 	}
 
 	token := result.String()
 	if token == "" {
 		diags.AddError("REST OAuth2 Token Error", "extracted token is empty")
-		return "", diags
+		return "", time.Time{}, diags
 	}
 
-	tflog.Debug(ctx, "REST OAuth2 login successful")
-	return token, diags
+	// Track the token lifetime so it can be refreshed before it expires
+	expiryPath := "expires_in"
+	if !data.OAuth2RestExpiryPath.IsNull() && !data.OAuth2RestExpiryPath.IsUnknown() {
+		expiryPath = data.OAuth2RestExpiryPath.ValueString()
+	}
+	var expiry time.Time
+	if expiryResult := gjson.Get(string(bodyBytes), expiryPath); expiryResult.Exists() {
+		expiry, err = parseTokenExpiry(expiryResult.String(), time.Now())
+		if err != nil {
+			diags.AddError("REST OAuth2 Token Expiry Error", err.Error())
+			return "", time.Time{}, diags
+		}
+	}
+
+	tflog.Debug(ctx, "REST OAuth2 login successful", map[string]any{
+		"expiry": expiry,
+	})
+	return token, expiry, diags
 }
 
 // Resources defines the resources implemented in the provider.
@@ -400,6 +1057,7 @@ func (p *GraphqlProvider) Resources(ctx context.Context) []func() resource.Resou
 func (p *GraphqlProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewGraphqlQueryDataSource,
+		NewGraphqlSubscriptionWaitDataSource,
 	}
 }
 
@@ -535,9 +1193,72 @@ type GraphqlQueryDataSourceModel struct {
 
 // graphqlProviderConfig holds the provider configuration
 type graphqlProviderConfig struct {
-	GQLServerUrl                string
-	RequestHeaders              map[string]interface{}
-	RequestAuthorizationHeaders map[string]interface{}
-	QueryRateLimitDelay         time.Duration
-	MutationRateLimitDelay      time.Duration
+	GQLServerUrl           string
+	RequestHeaders         map[string]interface{}
+	HTTPClient             *http.Client
+	TokenSource            *tokenSource
+	Signer                 *awsSigV4Signer
+	TokenFile              *cachedFile
+	HeadersFile            *cachedFile
+	SensitiveVariablePaths [][]string
+	SessionSource          *tokenSource
+	// OmitErrorBodies leaves response bodies out of error diagnostics
+	OmitErrorBodies        bool
+	CSRFHeaderName         string
+	QueryRateLimitDelay    time.Duration
+	MutationRateLimitDelay time.Duration
+	QueryRateLimiter       *requestRateLimiter
+	MutationRateLimiter    *requestRateLimiter
+	CostThrottle           *costThrottle
+	RequestSlots           requestSemaphore
+	PersistedQueries       *persistedQueries
+	QueryMethod            string
+	ETags                  *etagCache
+	Batcher                *queryBatcher
+	Retry                  *retryPolicy
+	Body                   *bodySettings
+}
+
+// withoutAuthentication returns a copy of the configuration that sends
+// requests without provider-managed credentials, for use by login flows.
+func (c *graphqlProviderConfig) withoutAuthentication() *graphqlProviderConfig {
+	clone := *c
+	clone.TokenSource = nil
+	clone.Signer = nil
+	clone.TokenFile = nil
+	clone.SessionSource = nil
+	// Never put login credentials in a URL
+	clone.QueryMethod = http.MethodPost
+	// Login requests must not share a batch with authenticated requests
+	clone.Batcher = nil
+	// Login error responses may echo the submitted credentials
+	clone.OmitErrorBodies = true
+	return &clone
+}
+
+// httpClient returns the configured HTTP client, falling back to a default
+// client for configurations that were not built by Configure.
+func (c *graphqlProviderConfig) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// retryPolicy returns the configured retry settings, falling back to the
+// defaults for configurations that were not built by Configure.
+func (c *graphqlProviderConfig) retryPolicy() retryPolicy {
+	if c.Retry != nil {
+		return *c.Retry
+	}
+	return defaultRetryPolicy
+}
+
+// bodySettings returns the configured body encoding settings, falling back to
+// the defaults for configurations that were not built by Configure.
+func (c *graphqlProviderConfig) bodySettings() bodySettings {
+	if c.Body != nil {
+		return *c.Body
+	}
+	return defaultBodySettings
 }
@@ -8,6 +8,7 @@ import (
 	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/kalenarndt/terraform-provider-graphql/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -86,11 +87,12 @@ func TestGraphqlProvider_DataSources(t *testing.T) {
 	p := &GraphqlProvider{}
 	datasources := p.DataSources(context.Background())
 
-	assert.Len(t, datasources, 1)
+	assert.Len(t, datasources, 2)
 
-	// Test that the datasource factory returns a valid datasource
-	datasource := datasources[0]()
-	require.NotNil(t, datasource)
+	// Test that the datasource factories return valid datasources
+	for _, factory := range datasources {
+		require.NotNil(t, factory())
+	}
 }
 
 func TestNew(t *testing.T) {
@@ -195,3 +197,47 @@ func TestDiagnosticsToString(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     GraphqlProviderModel
+		expected []string
+	}{
+		{
+			name:     "none",
+			data:     GraphqlProviderModel{},
+			expected: nil,
+		},
+		{
+			name:     "login query",
+			data:     GraphqlProviderModel{OAuth2LoginQuery: types.StringValue("mutation { login }")},
+			expected: []string{"`oauth2_login_query`"},
+		},
+		{
+			name:     "login query and REST URL",
+			data:     GraphqlProviderModel{OAuth2LoginQuery: types.StringValue("mutation { login }"), OAuth2RestURL: types.StringValue("https://auth.example.com/token")},
+			expected: []string{"`oauth2_login_query`", "`oauth2_rest_url`"},
+		},
+		{
+			name: "login query and JWT assertion",
+			data: GraphqlProviderModel{
+				OAuth2LoginQuery:   types.StringValue("mutation { login }"),
+				OAuth2RestURL:      types.StringValue("https://auth.example.com/token"),
+				OAuth2JWTAssertion: &OAuth2JWTAssertionModel{},
+			},
+			expected: []string{"`oauth2_login_query`", "`oauth2_rest_url` with `oauth2_jwt_assertion`"},
+		},
+		{
+			name:     "JWT assertion",
+			data:     GraphqlProviderModel{OAuth2RestURL: types.StringValue("https://auth.example.com/token"), OAuth2JWTAssertion: &OAuth2JWTAssertionModel{}},
+			expected: []string{"`oauth2_rest_url` with `oauth2_jwt_assertion`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, authenticationModes(tt.data))
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// tokenKind is the kind of a GraphQL lexical token.
+type tokenKind int
+
+const (
+	tokenPunctuator tokenKind = iota
+	tokenName
+	tokenNumber
+	tokenString
+)
+
+// token is a GraphQL lexical token. Whitespace, commas and comments are
+// insignificant and dropped.
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// tokenizeGraphQL splits a GraphQL document into tokens.
+func tokenizeGraphQL(document string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(document) && document[i] != '\n' && document[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(document[i:], "..."):
+			tokens = append(tokens, token{kind: tokenPunctuator, value: "..."})
+			i += 3
+		case strings.ContainsRune("!$&()=:@[]{}|", rune(c)):
+			tokens = append(tokens, token{kind: tokenPunctuator, value: string(c)})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(document) && (document[i] == '_' || isLetter(document[i]) || isDigit(document[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: document[start:i]})
+		case c == '-' || isDigit(c):
+			start := i
+			i++
+			for i < len(document) && (isDigit(document[i]) || strings.ContainsRune(".eE+-", rune(document[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: document[start:i]})
+		case strings.HasPrefix(document[i:], `"""`):
+			end := strings.Index(strings.ReplaceAll(document[i+3:], `\"""`, "xxxx"), `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated block string")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: document[i : i+3+end+3]})
+			i += 3 + end + 3
+		case c == '"':
+			start := i
+			i++
+			for i < len(document) && document[i] != '"' {
+				if document[i] == '\\' {
+					i++
+				}
+				if i >= len(document) || document[i] == '\n' {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				i++
+			}
+			if i >= len(document) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, value: document[start:i]})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// printTokens joins tokens back into a document.
+func printTokens(tokens []token) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		// Keep variables and directives attached to their names
+		if i > 0 && tokens[i-1].value != "$" && tokens[i-1].value != "@" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.value)
+	}
+	return b.String()
+}
+
+// matchingClose returns the index of the token closing the bracket opened at
+// tokens[open], or -1.
+func matchingClose(tokens []token, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		if tokens[i].kind != tokenPunctuator {
+			continue
+		}
+		switch tokens[i].value {
+		case "{", "(", "[":
+			depth++
+		case "}", ")", "]":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// coalescableQuery is a query whose instances with different variables can
+// be merged into a single document by aliasing its top-level fields.
+type coalescableQuery struct {
+	name                string
+	variableDefinitions []token
+	// selections are the top-level fields without their aliases, and keys
+	// the response keys they are returned under
+	selections [][]token
+	keys       []string
+	fragments  []token
+}
+
+// parseCoalescableQuery parses a query for coalescing. It reports false for
+// documents that cannot be merged safely: anything but a single query,
+// operation directives, top-level fragment spreads, or fragments that use
+// variables.
+func parseCoalescableQuery(query string) (*coalescableQuery, bool) {
+	tokens, err := tokenizeGraphQL(query)
+	if err != nil {
+		return nil, false
+	}
+
+	q := &coalescableQuery{}
+	operations := 0
+	for i := 0; i < len(tokens); {
+		t := tokens[i]
+		switch {
+		case t.kind == tokenName && t.value == "fragment":
+			open := i
+			for open < len(tokens) && tokens[open].value != "{" {
+				open++
+			}
+			end := matchingClose(tokens, open)
+			if end < 0 {
+				return nil, false
+			}
+			for _, ft := range tokens[i : end+1] {
+				if ft.value == "$" {
+					return nil, false
+				}
+			}
+			q.fragments = append(q.fragments, tokens[i:end+1]...)
+			i = end + 1
+		case t.value == "{" || (t.kind == tokenName && t.value == "query"):
+			operations++
+			end, ok := q.parseOperation(tokens, i)
+			if !ok {
+				return nil, false
+			}
+			i = end + 1
+		default:
+			// Mutations, subscriptions or anything unexpected
+			return nil, false
+		}
+	}
+
+	if operations != 1 || len(q.selections) == 0 {
+		return nil, false
+	}
+	return q, true
+}
+
+// parseOperation parses the query starting at tokens[start] and returns the
+// index of its closing brace.
+func (q *coalescableQuery) parseOperation(tokens []token, start int) (int, bool) {
+	i := start
+	if tokens[i].value == "query" {
+		i++
+		if i < len(tokens) && tokens[i].kind == tokenName {
+			q.name = tokens[i].value
+			i++
+		}
+		if i < len(tokens) && tokens[i].value == "(" {
+			end := matchingClose(tokens, i)
+			if end < 0 {
+				return 0, false
+			}
+			q.variableDefinitions = tokens[i+1 : end]
+			i = end + 1
+		}
+	}
+	if i >= len(tokens) || tokens[i].value != "{" {
+		// Operation directives are not supported
+		return 0, false
+	}
+
+	end := matchingClose(tokens, i)
+	if end < 0 {
+		return 0, false
+	}
+
+	// Split the selection set into its top-level fields
+	body := tokens[i+1 : end]
+	depth := 0
+	selectionStart := -1
+	for j := 0; j <= len(body); j++ {
+		if j < len(body) {
+			t := body[j]
+			if t.kind == tokenPunctuator {
+				switch t.value {
+				case "{", "(", "[":
+					depth++
+				case "}", ")", "]":
+					depth--
+				case "...":
+					if depth == 0 {
+						return 0, false
+					}
+				}
+			}
+			startsSelection := depth == 0 && t.kind == tokenName && (j == 0 || (body[j-1].value != ":" && body[j-1].value != "@"))
+			if !startsSelection {
+				continue
+			}
+		}
+		if selectionStart >= 0 {
+			q.addSelection(body[selectionStart:j])
+		}
+		selectionStart = j
+	}
+	return end, true
+}
+
+// addSelection records a top-level field, removing its alias.
+func (q *coalescableQuery) addSelection(selection []token) {
+	key := selection[0].value
+	if len(selection) > 2 && selection[1].value == ":" {
+		selection = selection[2:]
+	}
+	q.keys = append(q.keys, key)
+	q.selections = append(q.selections, selection)
+}
+
+// coalescedAlias returns the alias of a top-level field of the instance.
+func coalescedAlias(instance int, key string) string {
+	return fmt.Sprintf("c%d__%s", instance, key)
+}
+
+// coalescedVariable returns the name of a variable of the instance.
+func coalescedVariable(instance int, name string) string {
+	return fmt.Sprintf("%s__c%d", name, instance)
+}
+
+// coalescedVariablePaths returns sensitive variable paths that also match
+// the renamed variables of each instance.
+func coalescedVariablePaths(paths [][]string, instances int) [][]string {
+	renamed := append([][]string{}, paths...)
+	for instance := 0; instance < instances; instance++ {
+		for _, p := range paths {
+			if len(p) == 0 || p[0] == "*" {
+				continue
+			}
+			renamed = append(renamed, append([]string{coalescedVariable(instance, p[0])}, p[1:]...))
+		}
+	}
+	return renamed
+}
+
+// renameVariables returns the tokens with every variable renamed for the
+// instance.
+func renameVariables(tokens []token, instance int) []token {
+	renamed := make([]token, len(tokens))
+	copy(renamed, tokens)
+	for i := 1; i < len(renamed); i++ {
+		if renamed[i-1].value == "$" && renamed[i].kind == tokenName {
+			renamed[i].value = coalescedVariable(instance, renamed[i].value)
+		}
+	}
+	return renamed
+}
+
+// merge returns a document querying every instance, and the variables for it.
+func (q *coalescableQuery) merge(variables []map[string]interface{}) (string, map[string]interface{}) {
+	mergedVariables := make(map[string]interface{})
+	var definitions, selections []token
+	for instance, instanceVariables := range variables {
+		definitions = append(definitions, renameVariables(q.variableDefinitions, instance)...)
+		for i, selection := range q.selections {
+			selections = append(selections,
+				token{kind: tokenName, value: coalescedAlias(instance, q.keys[i])},
+				token{kind: tokenPunctuator, value: ":"},
+			)
+			selections = append(selections, renameVariables(selection, instance)...)
+		}
+		for name, value := range instanceVariables {
+			mergedVariables[coalescedVariable(instance, name)] = value
+		}
+	}
+
+	tokens := []token{{kind: tokenName, value: "query"}}
+	if q.name != "" {
+		tokens = append(tokens, token{kind: tokenName, value: q.name})
+	}
+	if len(definitions) > 0 {
+		tokens = append(tokens, token{kind: tokenPunctuator, value: "("})
+		tokens = append(tokens, definitions...)
+		tokens = append(tokens, token{kind: tokenPunctuator, value: ")"})
+	}
+	tokens = append(tokens, token{kind: tokenPunctuator, value: "{"})
+	tokens = append(tokens, selections...)
+	tokens = append(tokens, token{kind: tokenPunctuator, value: "}"})
+	tokens = append(tokens, q.fragments...)
+	return printTokens(tokens), mergedVariables
+}
+
+// split divides the response to a merged document into the response each
+// instance would have received. Errors with a path are given to the
+// instance they belong to; other errors to every instance.
+func (q *coalescableQuery) split(body []byte, instances int) ([][]byte, error) {
+	var merged struct {
+		Data       map[string]json.RawMessage `json:"data"`
+		Errors     []map[string]interface{}   `json:"errors"`
+		Extensions json.RawMessage            `json:"extensions"`
+	}
+	if err := json.Unmarshal(body, &merged); err != nil {
+		return nil, err
+	}
+
+	type instanceResponse struct {
+		Data       map[string]json.RawMessage `json:"data"`
+		Errors     []map[string]interface{}   `json:"errors,omitempty"`
+		Extensions json.RawMessage            `json:"extensions,omitempty"`
+	}
+	responses := make([]instanceResponse, instances)
+	for instance := range responses {
+		responses[instance].Extensions = merged.Extensions
+		if merged.Data == nil {
+			continue
+		}
+		responses[instance].Data = make(map[string]json.RawMessage, len(q.keys))
+		for _, key := range q.keys {
+			if value, ok := merged.Data[coalescedAlias(instance, key)]; ok {
+				responses[instance].Data[key] = value
+			}
+		}
+	}
+
+	for _, gqlErr := range merged.Errors {
+		instance, key, ok := q.errorInstance(gqlErr, instances)
+		if !ok {
+			for i := range responses {
+				responses[i].Errors = append(responses[i].Errors, gqlErr)
+			}
+			continue
+		}
+		path := gqlErr["path"].([]interface{})
+		instanceErr := make(map[string]interface{}, len(gqlErr))
+		for k, v := range gqlErr {
+			instanceErr[k] = v
+		}
+		instanceErr["path"] = append([]interface{}{key}, path[1:]...)
+		responses[instance].Errors = append(responses[instance].Errors, instanceErr)
+	}
+
+	bodies := make([][]byte, instances)
+	for i, response := range responses {
+		encoded, err := json.Marshal(response)
+		if err != nil {
+			return nil, err
+		}
+		bodies[i] = encoded
+	}
+	return bodies, nil
+}
+
+// errorInstance returns the instance and original response key an error's
+// path refers to.
+func (q *coalescableQuery) errorInstance(gqlErr map[string]interface{}, instances int) (int, string, bool) {
+	path, ok := gqlErr["path"].([]interface{})
+	if !ok || len(path) == 0 {
+		return 0, "", false
+	}
+	alias, ok := path[0].(string)
+	if !ok {
+		return 0, "", false
+	}
+	for instance := 0; instance < instances; instance++ {
+		for _, key := range q.keys {
+			if alias == coalescedAlias(instance, key) {
+				return instance, key, true
+			}
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,210 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoalescableQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		coalescable bool
+		keys        []string
+	}{
+		{name: "anonymous", query: "{ viewer { login } }", coalescable: true, keys: []string{"viewer"}},
+		{name: "variables and alias", query: "query GetUser($id: ID!, $first: Int = 10) {\n  user: node(id: $id) { id }\n  # comment\n  repos(first: $first, after: \"a, b\") { totalCount }\n}", coalescable: true, keys: []string{"user", "repos"}},
+		{name: "field directive", query: "query($id: ID!, $skip: Boolean!) { node(id: $id) @skip(if: $skip) { id } }", coalescable: true, keys: []string{"node"}},
+		{name: "fragment without variables", query: "query($id: ID!) { node(id: $id) { ...Fields } } fragment Fields on Node { id }", coalescable: true, keys: []string{"node"}},
+		{name: "mutation", query: "mutation($id: ID!) { delete(id: $id) { id } }"},
+		{name: "two operations", query: "query A { a } query B { b }"},
+		{name: "operation directive", query: "query Q @cached { a }"},
+		{name: "top-level spread", query: "query { ...Root } fragment Root on Query { a }"},
+		{name: "fragment with variables", query: "query($id: ID!) { ...Root } fragment Root on Query { node(id: $id) { id } }"},
+		{name: "unterminated string", query: `{ node(id: "1) { id } }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, ok := parseCoalescableQuery(tt.query)
+			assert.Equal(t, tt.coalescable, ok)
+			if ok {
+				assert.Equal(t, tt.keys, query.keys)
+			}
+		})
+	}
+}
+
+func TestCoalescableQuery_Merge(t *testing.T) {
+	query, ok := parseCoalescableQuery(`query GetUser($id: ID!) { user: node(id: $id) { id ...Fields } } fragment Fields on Node { __typename }`)
+	require.True(t, ok)
+
+	document, variables := query.merge([]map[string]interface{}{{"id": "1"}, {"id": "2"}})
+	assert.Equal(t, "query GetUser ( $id__c0 : ID ! $id__c1 : ID ! ) { c0__user : node ( id : $id__c0 ) { id ... Fields } c1__user : node ( id : $id__c1 ) { id ... Fields } } fragment Fields on Node { __typename }", document)
+	assert.Equal(t, map[string]interface{}{"id__c0": "1", "id__c1": "2"}, variables)
+}
+
+func TestCoalescableQuery_Split(t *testing.T) {
+	query, ok := parseCoalescableQuery(`query($id: ID!) { node(id: $id) { id name } }`)
+	require.True(t, ok)
+
+	bodies, err := query.split([]byte(`{
+		"data": {"c0__node": {"id": "1", "name": "a"}, "c1__node": null},
+		"errors": [
+			{"message": "not found", "path": ["c1__node"]},
+			{"message": "deprecated field", "path": ["c0__node", "name"]},
+			{"message": "rate limited"}
+		],
+		"extensions": {"cost": 2}
+	}`), 2)
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+	assert.JSONEq(t, `{
+		"data": {"node": {"id": "1", "name": "a"}},
+		"errors": [{"message": "deprecated field", "path": ["node", "name"]}, {"message": "rate limited"}],
+		"extensions": {"cost": 2}
+	}`, string(bodies[0]))
+	assert.JSONEq(t, `{
+		"data": {"node": null},
+		"errors": [{"message": "not found", "path": ["node"]}, {"message": "rate limited"}],
+		"extensions": {"cost": 2}
+	}`, string(bodies[1]))
+}
+
+func TestQueryBatcher_Alias(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		var body graphQLBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if !strings.Contains(body.Query, "__c") {
+			_, _ = fmt.Fprintf(w, `{"data":{"node":{"id":%q}}}`, body.Variables["id"])
+			return
+		}
+		fields := make([]string, 0, len(body.Variables))
+		for i := 0; i < len(body.Variables); i++ {
+			fields = append(fields, fmt.Sprintf(`"c%d__node":{"id":%q}`, i, body.Variables[fmt.Sprintf("id__c%d", i)]))
+		}
+		_, _ = w.Write([]byte(`{"data":{` + strings.Join(fields, ",") + `}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Batcher:        &queryBatcher{mode: batchingModeAlias, window: 100 * time.Millisecond, maxSize: 3},
+	}
+
+	ids := []string{"1", "2", "3"}
+	assert.Equal(t, ids, executeConcurrently(t, config, ids))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "queries with the same document are merged into one request")
+}
+
+func TestQueryBatcher_AliasNullData(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		var body graphQLBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if strings.Contains(body.Query, "__c") {
+			// A non-null root field failed, nulling the whole merged result
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"node not found","path":["c0__node"]}]}`))
+			return
+		}
+		if body.Variables["id"] == "2" {
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"node not found","path":["node"]}]}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"data":{"node":{"id":%q}}}`, body.Variables["id"])
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		Batcher:        &queryBatcher{mode: batchingModeAlias, window: 100 * time.Millisecond, maxSize: 3},
+	}
+
+	responses := make([]*GqlQueryResponse, 3)
+	var wg sync.WaitGroup
+	for i, id := range []string{"1", "2", "3"} {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			resp, _, diags := executeGraphQLRequestFramework(context.Background(), "query($id: ID!) { node(id: $id) { id } }", map[string]interface{}{"id": id}, config)
+			assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+			responses[i] = resp
+		}(i, id)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests), "the merged query is followed by individual requests")
+	for i, resp := range responses {
+		require.NotNil(t, resp)
+		if i == 1 {
+			require.Len(t, resp.Errors, 1, "only the failing query reports the error")
+			continue
+		}
+		assert.Empty(t, resp.Errors)
+		assert.NotNil(t, resp.Data["node"], "healthy queries keep their data")
+	}
+}
+
+func TestQueryBatcher_AliasRedactsEveryOperation(t *testing.T) {
+	var output bytes.Buffer
+	rootCtx := tflogtest.RootLogger(context.Background(), &output)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"c0__login":{"ok":true},"c1__login":{"ok":true}}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:           server.URL,
+		RequestHeaders:         map[string]interface{}{},
+		SensitiveVariablePaths: parseSensitiveVariablePaths([]string{"input.credential"}),
+		Batcher:                &queryBatcher{mode: batchingModeAlias, window: 100 * time.Millisecond, maxSize: 2},
+	}
+
+	var wg sync.WaitGroup
+	for _, password := range []string{"first-secret-password", "second-secret-password"} {
+		wg.Add(1)
+		go func(password string) {
+			defer wg.Done()
+			variables := map[string]interface{}{"input": map[string]interface{}{"credential": password}}
+			ctx := config.logContext(rootCtx, variables)
+			_, _, diags := executeGraphQLRequestFramework(ctx, "query($input: LoginInput!) { login(input: $input) { ok } }", variables, config)
+			assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		}(password)
+	}
+	wg.Wait()
+
+	assert.Contains(t, output.String(), "Sending coalesced GraphQL query")
+	assert.NotContains(t, output.String(), "first-secret-password")
+	assert.NotContains(t, output.String(), "second-secret-password")
+}
+
+func TestCoalescedVariablePaths(t *testing.T) {
+	paths := coalescedVariablePaths([][]string{{"input", "password"}, {"*", "token"}}, 2)
+	assert.Equal(t, [][]string{
+		{"input", "password"},
+		{"*", "token"},
+		{"input__c0", "password"},
+		{"input__c1", "password"},
+	}, paths)
+}
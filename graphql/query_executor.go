@@ -4,49 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"golang.org/x/time/rate"
+	gqlerrors "github.com/kalenarndt/terraform-provider-graphql/internal/errors"
 )
 
-// Global rate limiters for coordination across all requests
-var (
-	queryRateLimiter    *rate.Limiter
-	mutationRateLimiter *rate.Limiter
-	rateLimitMutex      sync.Mutex
-)
-
-// initializeRateLimiters initializes the global rate limiters
-func initializeRateLimiters(queryDelay, mutationDelay time.Duration) {
-	rateLimitMutex.Lock()
-	defer rateLimitMutex.Unlock()
-
-	if queryDelay > 0 {
-		queryRateLimiter = rate.NewLimiter(rate.Every(queryDelay), 1)
-	}
-	if mutationDelay > 0 {
-		mutationRateLimiter = rate.NewLimiter(rate.Every(mutationDelay), 1)
-	}
-}
-
 // queryExecuteFramework executes a GraphQL query using the new framework patterns
 func queryExecuteFramework(ctx context.Context, config *graphqlProviderConfig, query, variableSource string, usePagination bool) (*GqlQueryResponse, []byte, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	tflog.Debug(ctx, "Executing GraphQL query", map[string]any{
-		"query":          query,
-		"variableSource": variableSource,
-		"usePagination":  usePagination,
-	})
-
 	var inputVariables map[string]interface{}
 	if variableSource != "" {
 		if err := json.Unmarshal([]byte(variableSource), &inputVariables); err != nil {
@@ -55,8 +28,17 @@ func queryExecuteFramework(ctx context.Context, config *graphqlProviderConfig, q
 		}
 	}
 
+	// Mask credentials and sensitive variable values in everything logged below
+	ctx = config.logContext(ctx, inputVariables)
+
+	tflog.Debug(ctx, "Executing GraphQL query", map[string]any{
+		"query":          query,
+		"variableSource": variableSource,
+		"usePagination":  usePagination,
+	})
+
 	tflog.Debug(ctx, "Parsed variables", map[string]any{
-		"inputVariables": inputVariables,
+		"inputVariables": config.redactVariables(inputVariables),
 	})
 
 	if usePagination {
@@ -121,151 +103,378 @@ func recursivelyPrepareVariables(data interface{}) interface{} {
 // executeGraphQLRequestFramework executes a GraphQL request with improved rate limiting support
 func executeGraphQLRequestFramework(ctx context.Context, query string, variables map[string]interface{}, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	maxRetries := 5
-	baseDelay := time.Second
+	retry := config.retryPolicy()
 
-	// Determine if this is a mutation or query based on the query content
-	isMutation := strings.Contains(strings.ToLower(query), "mutation")
+	isMutation := isMutationQuery(query)
 
-	// Initialize rate limiters if not already done
-	if queryRateLimiter == nil || mutationRateLimiter == nil {
-		initializeRateLimiters(config.QueryRateLimitDelay, config.MutationRateLimitDelay)
-	}
+	reauthenticated := false
+	for attempt := 0; attempt <= retry.MaxRetries; attempt++ {
+		attemptStart := time.Now()
+		queryResponse, bodyBytes, requestErr, attemptDiags := executeSingleGraphQLRequest(ctx, query, variables, config)
 
-	// Wait for rate limiter before making the request
-	var limiter *rate.Limiter
-	if isMutation {
-		limiter = mutationRateLimiter
-	} else {
-		limiter = queryRateLimiter
-	}
-
-	if limiter != nil {
-		if err := limiter.Wait(ctx); err != nil {
-			diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for rate limiter: %v", err))
-			return nil, nil, diags
+		// If the token or session was rejected, log in again and retry once
+		canReauthenticate := config.TokenSource != nil || config.SessionSource != nil
+		if canReauthenticate && !reauthenticated && isAuthenticationError(requestErr, queryResponse) {
+			tflog.Debug(ctx, "Request was rejected as unauthenticated, logging in again and retrying", map[string]any{
+				"attempt":   attempt + 1,
+				"operation": isMutation,
+			})
+			reauthenticated = true
+			if config.TokenSource != nil {
+				config.TokenSource.Expire(attemptStart)
+			}
+			if config.SessionSource != nil {
+				config.SessionSource.Expire(attemptStart)
+			}
+			attempt--
+			continue
 		}
-	}
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		queryResponse, bodyBytes, attemptDiags := executeSingleGraphQLRequest(ctx, query, variables, config)
 
 		// If no errors, return success
 		if !attemptDiags.HasError() {
 			return queryResponse, bodyBytes, attemptDiags
 		}
 
-		// Check if this is a rate limit error
-		if isRateLimitError(attemptDiags) {
-			if attempt < maxRetries {
-				// Try to parse retry time from the error response
-				retryDelay := parseRetryDelay(attemptDiags)
-				if retryDelay > 0 {
-					tflog.Debug(ctx, "Rate limited, retrying with API-specified delay", map[string]any{
-						"attempt":    attempt + 1,
-						"retryDelay": retryDelay,
-						"operation":  isMutation,
-					})
-					time.Sleep(retryDelay)
-				} else {
-					// Fallback to exponential backoff with jitter
-					delay := time.Duration(attempt+1) * baseDelay
-					// Add jitter to prevent thundering herd
-					jitter := time.Duration(attempt+1) * 100 * time.Millisecond
-					delay += jitter
-					tflog.Debug(ctx, "Rate limited, retrying with exponential backoff", map[string]any{
-						"attempt":   attempt + 1,
-						"delay":     delay,
-						"operation": isMutation,
-					})
-					time.Sleep(delay)
-				}
-				continue
+		if requestErr == nil || ctx.Err() != nil || !shouldRetryRequest(requestErr, isMutation, attempt, retry.MaxRetries) {
+			if requestErr != nil {
+				tflog.Debug(ctx, "Request failed, not retrying", map[string]any{
+					"attempt":    attempt + 1,
+					"errorType":  requestErr.Type(),
+					"statusCode": requestErr.StatusCode,
+					"operation":  isMutation,
+				})
 			}
+			return queryResponse, bodyBytes, attemptDiags
 		}
 
-		// Check if this is a business logic error (don't retry these)
-		if isBusinessLogicError(attemptDiags) {
-			tflog.Debug(ctx, "Business logic error, not retrying", map[string]any{
-				"attempt":   attempt + 1,
-				"operation": isMutation,
-			})
+		// Prefer the delay the API asked for
+		delay := parseRetryDelay(requestErr, time.Now())
+		if delay <= 0 {
+			delay = retry.backoff(attempt)
+		}
+		tflog.Debug(ctx, "Request failed, retrying", map[string]any{
+			"attempt":    attempt + 1,
+			"errorType":  requestErr.Type(),
+			"statusCode": requestErr.StatusCode,
+			"delay":      delay,
+			"operation":  isMutation,
+		})
+		if err := sleepContext(ctx, delay); err != nil {
+			attemptDiags.AddError("Request Cancelled", fmt.Sprintf("stopped retrying after %d attempts: %v", attempt+1, err))
 			return queryResponse, bodyBytes, attemptDiags
 		}
-
-		// If not a rate limit error or max retries reached, return the error
-		return queryResponse, bodyBytes, attemptDiags
 	}
 
 	return nil, nil, diags
 }
 
-// parseRetryDelay extracts the retryAfterNS from rate limit error responses
-func parseRetryDelay(diags diag.Diagnostics) time.Duration {
-	for _, d := range diags {
-		if strings.Contains(d.Detail(), "HTTP 429") {
-			// Try to extract retryAfterNS from the error message
-			if strings.Contains(d.Detail(), "retryAfterNS") {
-				// Look for retryAfterNS in the JSON response
-				start := strings.Index(d.Detail(), `"retryAfterNS":`)
-				if start != -1 {
-					start += len(`"retryAfterNS":`)
-					end := strings.Index(d.Detail()[start:], ",")
-					if end == -1 {
-						end = strings.Index(d.Detail()[start:], "}")
-					}
-					if end != -1 {
-						retryStr := strings.TrimSpace(d.Detail()[start : start+end])
-						if retry, err := strconv.ParseInt(retryStr, 10, 64); err == nil {
-							// Convert nanoseconds to duration
-							return time.Duration(retry) * time.Nanosecond
-						}
-					}
-				}
+// isMutationQuery determines if this is a mutation or query based on the query content
+func isMutationQuery(query string) bool {
+	return strings.Contains(strings.ToLower(query), "mutation")
+}
+
+// shouldRetryRequest decides whether a failed request is retried. Rate limits,
+// 502/503/504 responses and transient network errors are retried; everything
+// else fails fast. Mutations are only retried when the server cannot have run
+// them.
+func shouldRetryRequest(requestErr *gqlerrors.RequestError, isMutation bool, attempt, maxRetries int) bool {
+	errorType := requestErr.Type()
+	if !gqlerrors.ShouldRetry(errorType, attempt, maxRetries) {
+		return false
+	}
+	if errorType != gqlerrors.ErrorTypeNetwork {
+		return true
+	}
+
+	if requestErr.Err == nil {
+		if !gqlerrors.IsTransientStatusCode(requestErr.StatusCode) {
+			return false
+		}
+		// A gateway may fail after the upstream already ran the mutation; only
+		// a 503 with Retry-After says the request was turned away
+		if isMutation {
+			return requestErr.StatusCode == http.StatusServiceUnavailable && requestErr.Header.Get("Retry-After") != ""
+		}
+		return true
+	}
+
+	// A mutation that may have reached the server is not sent again, as it is
+	// not necessarily idempotent
+	if isMutation && !gqlerrors.IsConnectError(requestErr.Err) {
+		return false
+	}
+	return gqlerrors.IsTransientNetworkError(requestErr.Err)
+}
+
+// parseRetryDelay returns how long the server asked the client to wait
+// before retrying, or zero if it did not say. The standard Retry-After header
+// (seconds or an HTTP date) takes precedence over RateLimit-Reset style
+// headers and the vendor-specific retryAfterNS body field.
+func parseRetryDelay(requestErr *gqlerrors.RequestError, now time.Time) time.Duration {
+	if requestErr == nil {
+		return 0
+	}
+
+	if value := requestErr.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return positiveDuration(date.Sub(now))
+		}
+	}
+
+	// Reset headers are sent with every response, so they only say how long
+	// to wait when the request was actually rate limited
+	if requestErr.StatusCode == http.StatusTooManyRequests {
+		for _, name := range []string{"RateLimit-Reset", "X-RateLimit-Reset", "X-RateLimit-Reset-After", "X-Rate-Limit-Reset"} {
+			if delay := parseRateLimitReset(requestErr.Header.Get(name), now); delay > 0 {
+				return delay
 			}
 		}
 	}
-	return 0
+
+	return parseRetryAfterNS(requestErr.Body)
 }
 
-// executeSingleGraphQLRequest executes a single GraphQL request
-func executeSingleGraphQLRequest(ctx context.Context, query string, variables map[string]interface{}, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, diag.Diagnostics) {
+// parseRateLimitReset parses a rate limit reset header, which is either the
+// number of seconds until the limit resets or the Unix time at which it does.
+func parseRateLimitReset(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	// Values this large can only be Unix timestamps
+	if seconds > 1e9 {
+		return positiveDuration(time.Unix(int64(seconds), 0).Sub(now))
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// parseRetryAfterNS extracts the retryAfterNS field from a rate limit error body
+func parseRetryAfterNS(body string) time.Duration {
+	start := strings.Index(body, `"retryAfterNS":`)
+	if start == -1 {
+		return 0
+	}
+	start += len(`"retryAfterNS":`)
+	end := strings.IndexAny(body[start:], ",}")
+	if end == -1 {
+		return 0
+	}
+	retry, err := strconv.ParseInt(strings.TrimSpace(body[start:start+end]), 10, 64)
+	if err != nil || retry < 0 {
+		return 0
+	}
+	// Convert nanoseconds to duration
+	return time.Duration(retry) * time.Nanosecond
+}
+
+// positiveDuration returns d, or zero if it is negative.
+func positiveDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// sleepContext waits for the given duration, returning early with the
+// context's error if it is cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// graphQLRequest is a GraphQL operation to send to the server.
+type graphQLRequest struct {
+	Query      string
+	Variables  map[string]interface{}
+	Extensions map[string]interface{}
+	// OmitQuery leaves the document out of the body, e.g. when only the
+	// persisted query hash is sent
+	OmitQuery bool
+	// Uploads are sent as a multipart request
+	Uploads []fileUpload
+}
+
+// newGraphQLRequest returns the request for an operation, including any files
+// to upload with requests made with the context.
+func newGraphQLRequest(ctx context.Context, query string, variables map[string]interface{}) graphQLRequest {
+	return graphQLRequest{Query: query, Variables: variables, Uploads: fileUploadsFromContext(ctx)}
+}
+
+// body returns the JSON request body for the operation.
+func (r graphQLRequest) body() map[string]interface{} {
+	body := map[string]interface{}{
+		"variables": r.Variables,
+	}
+	if !r.OmitQuery {
+		body["query"] = r.Query
+	}
+	if len(r.Extensions) > 0 {
+		body["extensions"] = r.Extensions
+	}
+	return body
+}
+
+// executeSingleGraphQLRequest executes a single GraphQL request. When the
+// server cannot be reached or responds with an error status, the failure is
+// also returned as a RequestError for retry classification.
+func executeSingleGraphQLRequest(ctx context.Context, query string, variables map[string]interface{}, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, *gqlerrors.RequestError, diag.Diagnostics) {
+	request := newGraphQLRequest(ctx, query, variables)
+	// Batched queries are throttled once per batch instead
+	if config.batches(request) {
+		return config.Batcher.execute(ctx, request, config)
+	}
+
 	var diags diag.Diagnostics
+	// Wait for rate limiter before making the request
+	if err := config.rateLimiter(isMutationQuery(query)).Wait(ctx); err != nil {
+		diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for rate limiter: %v", err))
+		return nil, nil, nil, diags
+	}
+
+	// Wait until the query cost budget allows another request
+	if err := config.CostThrottle.Wait(ctx); err != nil {
+		diags.AddError("Rate Limiter Error", fmt.Sprintf("failed to wait for query cost budget: %v", err))
+		return nil, nil, nil, diags
+	}
 
-	// Prepare request body
-	requestBody := map[string]interface{}{
-		"query":     query,
-		"variables": variables,
+	if config.PersistedQueries != nil {
+		return config.PersistedQueries.execute(ctx, request, config)
 	}
+	return sendGraphQLRequest(ctx, request, config)
+}
+
+// sendGraphQLRequest sends a GraphQL request to the server and parses the response.
+func sendGraphQLRequest(ctx context.Context, request graphQLRequest, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, *gqlerrors.RequestError, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	query, variables := request.Query, request.Variables
 
 	queryBodyBuffer := &bytes.Buffer{}
-	if err := json.NewEncoder(queryBodyBuffer).Encode(requestBody); err != nil {
+	if err := json.NewEncoder(queryBodyBuffer).Encode(request.body()); err != nil {
 		diags.AddError("Request Encoding Error", fmt.Sprintf("failed to encode request body: %v", err))
-		return nil, nil, diags
+		return nil, nil, nil, diags
 	}
 
+	// Log the body with the same variables redacted as "variables"
+	loggedBody := request.body()
+	loggedBody["variables"] = config.redactVariables(variables)
 	tflog.Debug(ctx, "Sending GraphQL request", map[string]any{
 		"url":           config.GQLServerUrl,
-		"headers":       config.RequestHeaders,
-		"variables":     variables,
+		"headers":       redactHeaders(config.RequestHeaders),
+		"variables":     config.redactVariables(variables),
 		"query":         query,
-		"variablesJSON": string(queryBodyBuffer.Bytes()),
+		"variablesJSON": logValue(loggedBody),
 	})
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", config.GQLServerUrl, queryBodyBuffer)
-	if err != nil {
-		diags.AddError("Request Creation Error", fmt.Sprintf("failed to create request: %v", err))
-		return nil, nil, diags
+	// Create HTTP request. Queries may be sent as GET so HTTP caches apply.
+	requestURL := config.GQLServerUrl
+	requestBytes := queryBodyBuffer.Bytes()
+	method := config.requestMethod(query)
+	if method == http.MethodGet {
+		getURL, err := buildGetURL(config.GQLServerUrl, request)
+		if err != nil {
+			diags.AddError("Request Encoding Error", fmt.Sprintf("failed to encode GET request: %v", err))
+			return nil, nil, nil, diags
+		}
+		requestURL = getURL
+		requestBytes = nil
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("Accept", "application/json; charset=utf-8")
+	// Files for Upload variables are sent as a multipart request
+	contentType := "application/json; charset=utf-8"
+	if method == http.MethodPost && len(request.Uploads) > 0 {
+		multipartBody, multipartContentType, err := buildMultipartBody(request, request.Uploads)
+		if err != nil {
+			diags.AddError("File Upload Error", fmt.Sprintf("failed to build multipart request: %v", err))
+			return nil, nil, nil, diags
+		}
+		requestBytes = multipartBody
+		contentType = multipartContentType
+	}
 
-	// Add authorization headers
-	for key, value := range config.RequestAuthorizationHeaders {
-		req.Header.Set(key, fmt.Sprintf("%v", value))
+	resp, bodyBytes, requestErr, roundTripDiags := roundTrip(ctx, method, requestURL, requestBytes, contentType, isMutationQuery(query), config)
+	diags.Append(roundTripDiags...)
+	if resp == nil {
+		return nil, nil, requestErr, diags
+	}
+
+	responseType := resp.Header.Get("Content-Type")
+	if !isSuccessStatus(resp.StatusCode) {
+		errorResponse, requestErr, errorDiags := errorStatusResult(resp, bodyBytes, config)
+		diags.Append(errorDiags...)
+		return errorResponse, bodyBytes, requestErr, diags
+	}
+
+	// Merge @defer and @stream results so callers see a single response
+	if boundary, ok := incrementalBoundary(responseType); ok {
+		merged, err := mergeIncrementalResponse(bodyBytes, boundary)
+		if err != nil {
+			diags.AddError("Response Parsing Error", fmt.Sprintf("failed to merge incremental delivery response: %v", err))
+			return nil, nil, nil, diags
+		}
+		tflog.Debug(ctx, "Merged incremental delivery response", map[string]any{
+			"responseLength": len(merged),
+		})
+		bodyBytes = merged
+	}
+
+	var queryResponse GqlQueryResponse
+	if err := json.Unmarshal(bodyBytes, &queryResponse); err != nil {
+		diags.AddError("Response Parsing Error", fmt.Sprintf("failed to parse response: %v: %s", err, summarizeBody(responseType, bodyBytes)))
+		return nil, nil, nil, diags
+	}
+
+	return &queryResponse, bodyBytes, nil, diags
+}
+
+// setAuthHeaders adds the provider's credentials and custom headers to a
+// request.
+func setAuthHeaders(ctx context.Context, req *http.Request, config *graphqlProviderConfig) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// Add authorization header from the provider-managed token
+	if config.TokenSource != nil {
+		token, tokenDiags := config.TokenSource.Token(ctx)
+		diags.Append(tokenDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	// Add the CSRF token for cookie session logins
+	if config.SessionSource != nil {
+		csrfToken, sessionDiags := config.SessionSource.Token(ctx)
+		diags.Append(sessionDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		if csrfToken != "" {
+			req.Header.Set(config.CSRFHeaderName, csrfToken)
+		}
+	}
+
+	// Add the bearer token from token_file, re-read if it was rotated
+	if config.TokenFile != nil {
+		token, err := config.TokenFile.ReadToken()
+		if err != nil {
+			diags.AddError("Token File Error", fmt.Sprintf("failed to read token file: %v", err))
+			return diags
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	// Add custom headers
@@ -273,19 +482,101 @@ func executeSingleGraphQLRequest(ctx context.Context, query string, variables ma
 		req.Header.Set(key, fmt.Sprintf("%v", value))
 	}
 
+	// Add headers from headers_from_file, which take precedence over static headers
+	if config.HeadersFile != nil {
+		fileHeaders, err := config.HeadersFile.ReadHeaders()
+		if err != nil {
+			diags.AddError("Headers File Error", fmt.Sprintf("failed to read headers file: %v", err))
+			return diags
+		}
+		for key, value := range fileHeaders {
+			req.Header.Set(key, value)
+		}
+	}
+
+	return diags
+}
+
+// roundTrip sends an encoded GraphQL request with the provider's credentials
+// and headers, and reads the response body. The response is nil if no
+// usable response was received; its body has already been read and closed.
+func roundTrip(ctx context.Context, method, requestURL string, requestBytes []byte, contentType string, isMutation bool, config *graphqlProviderConfig) (*http.Response, []byte, *gqlerrors.RequestError, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// Compress large request bodies, e.g. mutations with large JSON variables
+	bodySettings := config.bodySettings()
+	compressed := false
+	if bodySettings.CompressRequests && len(requestBytes) >= minCompressedRequestSize {
+		gzipped, err := gzipBody(requestBytes)
+		if err != nil {
+			diags.AddError("Request Encoding Error", fmt.Sprintf("failed to compress request body: %v", err))
+			return nil, nil, nil, diags
+		}
+		requestBytes = gzipped
+		compressed = true
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(requestBytes))
+	if err != nil {
+		diags.AddError("Request Creation Error", fmt.Sprintf("failed to create request: %v", err))
+		return nil, nil, nil, diags
+	}
+
+	// Set headers
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", contentType)
+		if compressed {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+	}
+	req.Header.Set("Accept", graphqlAcceptHeader)
+	// Decompressed by readResponseBody, so the size limit applies to the
+	// decompressed body
+	req.Header.Set("Accept-Encoding", "gzip, br, zstd")
+
+	diags.Append(setAuthHeaders(ctx, req, config)...)
+	if diags.HasError() {
+		return nil, nil, nil, diags
+	}
+
+	// Revalidate a previously fetched response instead of downloading it again
+	cached, hasCached := config.ETags.Get(requestURL)
+	if method == http.MethodGet && hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	// Hold a request slot while the request is in flight. It is taken after
+	// the token above, as a login may need a slot of its own.
+	release, err := config.RequestSlots.Acquire(ctx)
+	if err != nil {
+		diags.AddError("Request Cancelled", fmt.Sprintf("failed to wait for a request slot: %v", err))
+		return nil, nil, nil, diags
+	}
+	defer release()
+
+	// Sign the request last so the signature covers every header and its
+	// timestamp is not aged by waiting for a slot
+	if config.Signer != nil {
+		if err := config.Signer.Sign(ctx, req, requestBytes); err != nil {
+			diags.AddError("AWS Credentials Error", err.Error())
+			return nil, nil, nil, diags
+		}
+	}
+
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := config.httpClient().Do(req)
 	if err != nil {
 		diags.AddError("HTTP Request Error", fmt.Sprintf("failed to execute request: %v", err))
-		return nil, nil, diags
+		return nil, nil, &gqlerrors.RequestError{URL: config.GQLServerUrl, Err: err}, diags
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		diags.AddError("Response Reading Error", fmt.Sprintf("failed to read response body: %v", err))
-		return nil, nil, diags
+	// Let adaptive rate limiting react to the server's remaining budget
+	config.rateLimiter(isMutation).Observe(ctx, resp.Header, time.Now())
+
+	bodyBytes, readDiags := config.readResponse(resp, config.GQLServerUrl)
+	diags.Append(readDiags...)
+	if diags.HasError() {
+		return nil, nil, nil, diags
 	}
 
 	tflog.Debug(ctx, "Received GraphQL response", map[string]any{
@@ -293,44 +584,101 @@ func executeSingleGraphQLRequest(ctx context.Context, query string, variables ma
 		"bodyLength": len(bodyBytes),
 	})
 
-	if resp.StatusCode != http.StatusOK {
-		diags.AddError("HTTP Error", fmt.Sprintf("received HTTP %d: %s", resp.StatusCode, string(bodyBytes)))
-		return nil, nil, diags
-	}
+	// Track the query cost budget reported by the server
+	config.CostThrottle.Observe(ctx, bodyBytes, time.Now())
 
-	var queryResponse GqlQueryResponse
-	if err := json.Unmarshal(bodyBytes, &queryResponse); err != nil {
-		diags.AddError("Response Parsing Error", fmt.Sprintf("failed to parse response: %v", err))
-		return nil, nil, diags
+	if method == http.MethodGet {
+		switch {
+		case resp.StatusCode == http.StatusNotModified && hasCached:
+			tflog.Debug(ctx, "Response not modified, using cached response", map[string]any{
+				"etag": cached.etag,
+			})
+			resp.StatusCode = http.StatusOK
+			resp.Header.Set("Content-Type", cached.contentType)
+			bodyBytes = cached.body
+		case resp.StatusCode == http.StatusOK:
+			config.ETags.Store(requestURL, resp.Header.Get("ETag"), resp.Header.Get("Content-Type"), bodyBytes)
+		}
 	}
 
-	return &queryResponse, bodyBytes, diags
+	return resp, bodyBytes, nil, diags
 }
 
-// isRateLimitError checks if the error is a rate limit error (429)
-func isRateLimitError(diags diag.Diagnostics) bool {
-	for _, d := range diags {
-		if strings.Contains(d.Detail(), "HTTP 429") || strings.Contains(d.Detail(), "Rate limit") {
-			return true
+// errorStatusResult describes a response with an error status as a
+// RequestError for retry classification and a diagnostic.
+func errorStatusResult(resp *http.Response, bodyBytes []byte, config *graphqlProviderConfig) (*GqlQueryResponse, *gqlerrors.RequestError, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	requestErr := &gqlerrors.RequestError{
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		Body:          string(bodyBytes),
+		URL:           config.GQLServerUrl,
+		GraphQLErrors: parseGraphQLErrors(bodyBytes),
+	}
+	// Servers following the GraphQL over HTTP specification describe
+	// request errors (e.g., validation failures) in a GraphQL response
+	var errorResponse *GqlQueryResponse
+	if len(requestErr.GraphQLErrors) > 0 {
+		errorResponse = &GqlQueryResponse{}
+		_ = json.Unmarshal(bodyBytes, errorResponse)
+	}
+	if config.OmitErrorBodies {
+		detail := fmt.Sprintf("received HTTP %d", resp.StatusCode)
+		if errorResponse != nil && len(errorResponse.Errors) > 0 {
+			detail += ": " + describeErrorResponse("", nil, errorResponse)
 		}
+		diags.AddError("HTTP Error", detail)
+		return errorResponse, requestErr, diags
 	}
-	return false
+	diags.AddError("HTTP Error", fmt.Sprintf("received HTTP %d: %s", resp.StatusCode, describeErrorResponse(resp.Header.Get("Content-Type"), bodyBytes, errorResponse)))
+	return errorResponse, requestErr, diags
 }
 
-// isBusinessLogicError checks if the error is a business logic error (not rate limit)
-func isBusinessLogicError(diags diag.Diagnostics) bool {
-	for _, d := range diags {
-		detail := d.Detail()
-		if strings.Contains(detail, "Can't enable multiple versions") ||
-			strings.Contains(detail, "already enabled") ||
-			strings.Contains(detail, "already exists") ||
-			strings.Contains(detail, "conflict") {
-			return true
+// isAuthenticationError checks if the request was rejected because the access
+// token is missing, invalid or expired: HTTP 401 or a GraphQL error with an
+// auth-class extensions.code. Error messages are not inspected, since servers
+// phrase permission errors ("Unauthorized to access field") the same way as
+// rejected credentials.
+func isAuthenticationError(err error, queryResponse *GqlQueryResponse) bool {
+	var requestErr *gqlerrors.RequestError
+	if errors.As(err, &requestErr) && requestErr != nil && requestErr.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+
+	if queryResponse == nil {
+		return false
+	}
+
+	for _, gqlErr := range queryResponse.Errors {
+		if code, ok := gqlErr.Extensions["code"].(string); ok {
+			switch strings.ToUpper(code) {
+			case "UNAUTHENTICATED", "UNAUTHORIZED", "INVALID_TOKEN", "TOKEN_EXPIRED":
+				return true
+			}
 		}
 	}
 	return false
 }
 
+// parseGraphQLErrors extracts the GraphQL errors from an error response body,
+// if it contains any.
+func parseGraphQLErrors(body []byte) []gqlerrors.GraphQLError {
+	var response GqlQueryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil
+	}
+
+	graphqlErrors := make([]gqlerrors.GraphQLError, 0, len(response.Errors))
+	for _, gqlErr := range response.Errors {
+		graphqlErrors = append(graphqlErrors, gqlerrors.GraphQLError{
+			Message:    gqlErr.Message,
+			Extensions: gqlErr.Extensions,
+		})
+	}
+	return graphqlErrors
+}
+
 // executeSingleQueryFramework executes a single GraphQL query
 func executeSingleQueryFramework(ctx context.Context, query string, inputVariables map[string]interface{}, config *graphqlProviderConfig) (*GqlQueryResponse, []byte, diag.Diagnostics) {
 	variables := prepareQueryVariables(inputVariables, "")
@@ -1,74 +1,22 @@
 package graphql
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-framework/diag"
+	gqlerrors "github.com/kalenarndt/terraform-provider-graphql/internal/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestInitializeRateLimiters(t *testing.T) {
-	tests := []struct {
-		name           string
-		queryDelay     time.Duration
-		mutationDelay  time.Duration
-		expectQuery    bool
-		expectMutation bool
-	}{
-		{
-			name:           "both delays set",
-			queryDelay:     100 * time.Millisecond,
-			mutationDelay:  200 * time.Millisecond,
-			expectQuery:    true,
-			expectMutation: true,
-		},
-		{
-			name:           "only query delay",
-			queryDelay:     100 * time.Millisecond,
-			mutationDelay:  0,
-			expectQuery:    true,
-			expectMutation: false,
-		},
-		{
-			name:           "only mutation delay",
-			queryDelay:     0,
-			mutationDelay:  200 * time.Millisecond,
-			expectQuery:    false,
-			expectMutation: true,
-		},
-		{
-			name:           "no delays",
-			queryDelay:     0,
-			mutationDelay:  0,
-			expectQuery:    false,
-			expectMutation: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Reset global rate limiters
-			queryRateLimiter = nil
-			mutationRateLimiter = nil
-
-			initializeRateLimiters(tt.queryDelay, tt.mutationDelay)
-
-			if tt.expectQuery {
-				assert.NotNil(t, queryRateLimiter)
-			} else {
-				assert.Nil(t, queryRateLimiter)
-			}
-
-			if tt.expectMutation {
-				assert.NotNil(t, mutationRateLimiter)
-			} else {
-				assert.Nil(t, mutationRateLimiter)
-			}
-		})
-	}
-}
-
 func TestPrepareQueryVariables(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -229,162 +177,271 @@ func TestRecursivelyPrepareVariables(t *testing.T) {
 	}
 }
 
-func TestIsRateLimitError(t *testing.T) {
+func TestShouldRetryRequest(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
 	tests := []struct {
-		name     string
-		diags    diag.Diagnostics
-		expected bool
+		name       string
+		err        *gqlerrors.RequestError
+		isMutation bool
+		attempt    int
+		expected   bool
 	}{
 		{
-			name:     "empty diagnostics",
-			diags:    diag.Diagnostics{},
-			expected: false,
+			name:     "rate limited",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusTooManyRequests},
+			expected: true,
 		},
 		{
-			name: "rate limit error",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Rate Limit", "HTTP 429 Too many requests"),
-			},
+			name:     "bad gateway",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusBadGateway},
 			expected: true,
 		},
 		{
-			name: "rate limit error with different message",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "Rate limit exceeded"),
-			},
+			name:     "service unavailable",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusServiceUnavailable},
 			expected: true,
 		},
 		{
-			name: "other error",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Other Error", "Something else"),
-			},
+			name:       "gateway timeout on a mutation",
+			err:        &gqlerrors.RequestError{StatusCode: http.StatusGatewayTimeout},
+			isMutation: true,
+			expected:   false,
+		},
+		{
+			name:       "bad gateway on a mutation",
+			err:        &gqlerrors.RequestError{StatusCode: http.StatusBadGateway},
+			isMutation: true,
+			expected:   false,
+		},
+		{
+			name:       "service unavailable on a mutation",
+			err:        &gqlerrors.RequestError{StatusCode: http.StatusServiceUnavailable},
+			isMutation: true,
+			expected:   false,
+		},
+		{
+			name:       "service unavailable with retry-after on a mutation",
+			err:        &gqlerrors.RequestError{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"5"}}},
+			isMutation: true,
+			expected:   true,
+		},
+		{
+			name:     "internal server error",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusInternalServerError},
 			expected: false,
 		},
 		{
-			name: "mixed diagnostics",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Rate Limit", "HTTP 429 Too many requests"),
-				diag.NewWarningDiagnostic("Warning", "Some warning"),
+			name: "bad request with GraphQL errors",
+			err: &gqlerrors.RequestError{
+				StatusCode:    http.StatusBadRequest,
+				GraphQLErrors: []gqlerrors.GraphQLError{{Message: "Cannot query field \"foo\""}},
 			},
+			expected: false,
+		},
+		{
+			name:     "forbidden",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusForbidden},
+			expected: false,
+		},
+		{
+			name:     "connection refused",
+			err:      &gqlerrors.RequestError{Err: dialErr},
 			expected: true,
 		},
+		{
+			name:     "connection reset",
+			err:      &gqlerrors.RequestError{Err: resetErr},
+			expected: true,
+		},
+		{
+			name:       "connection reset on a mutation",
+			err:        &gqlerrors.RequestError{Err: resetErr},
+			isMutation: true,
+			expected:   false,
+		},
+		{
+			name:       "connection refused on a mutation",
+			err:        &gqlerrors.RequestError{Err: dialErr},
+			isMutation: true,
+			expected:   true,
+		},
+		{
+			name:     "unsupported protocol",
+			err:      &gqlerrors.RequestError{Err: errors.New("unsupported protocol scheme \"ftp\"")},
+			expected: false,
+		},
+		{
+			name:     "retries exhausted",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusServiceUnavailable},
+			attempt:  3,
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isRateLimitError(tt.diags)
-			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.expected, shouldRetryRequest(tt.err, tt.isMutation, tt.attempt, 3))
 		})
 	}
 }
 
-func TestIsBusinessLogicError(t *testing.T) {
+func TestExecuteGraphQLRequestFramework_Retries(t *testing.T) {
 	tests := []struct {
-		name     string
-		diags    diag.Diagnostics
-		expected bool
+		name          string
+		statuses      []int
+		expectError   bool
+		expectedCalls int32
 	}{
 		{
-			name:     "empty diagnostics",
-			diags:    diag.Diagnostics{},
-			expected: false,
-		},
-		{
-			name: "business logic error - multiple versions",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "Can't enable multiple versions"),
-			},
-			expected: true,
-		},
-		{
-			name: "business logic error - already enabled",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "already enabled"),
-			},
-			expected: true,
-		},
-		{
-			name: "business logic error - already exists",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "already exists"),
-			},
-			expected: true,
+			name:          "transient errors are retried",
+			statuses:      []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expectedCalls: 3,
 		},
 		{
-			name: "business logic error - conflict",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "conflict"),
-			},
-			expected: true,
+			name:          "server errors fail fast",
+			statuses:      []int{http.StatusInternalServerError, http.StatusOK},
+			expectError:   true,
+			expectedCalls: 1,
 		},
 		{
-			name: "other error",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Other Error", "Something else"),
-			},
-			expected: false,
+			name:          "client errors fail fast",
+			statuses:      []int{http.StatusBadRequest, http.StatusOK},
+			expectError:   true,
+			expectedCalls: 1,
 		},
 		{
-			name: "mixed diagnostics",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "already enabled"),
-				diag.NewWarningDiagnostic("Warning", "Some warning"),
-			},
-			expected: true,
+			name:          "retries are limited",
+			statuses:      []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+			expectError:   true,
+			expectedCalls: 3,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isBusinessLogicError(tt.diags)
-			assert.Equal(t, tt.expected, result)
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.statuses[call-1])
+				_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+			}))
+			defer server.Close()
+
+			config := &graphqlProviderConfig{
+				GQLServerUrl:   server.URL,
+				RequestHeaders: map[string]interface{}{},
+				Retry:          &retryPolicy{MaxRetries: 2, BackoffBase: time.Millisecond, BackoffMax: time.Millisecond},
+			}
+
+			_, _, diags := executeGraphQLRequestFramework(context.Background(), "query { ok }", nil, config)
+			assert.Equal(t, tt.expectError, diags.HasError(), "diagnostics: %v", diags)
+			assert.Equal(t, tt.expectedCalls, atomic.LoadInt32(&calls))
 		})
 	}
 }
 
 func TestParseRetryDelay(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
 	tests := []struct {
 		name     string
-		diags    diag.Diagnostics
+		err      *gqlerrors.RequestError
 		expected time.Duration
 	}{
 		{
-			name:     "empty diagnostics",
-			diags:    diag.Diagnostics{},
+			name:     "no request error",
+			err:      nil,
 			expected: 0,
 		},
 		{
-			name: "no retry delay in diagnostics",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "Some error"),
-			},
+			name:     "no retry delay",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Body: "Some error"},
 			expected: 0,
 		},
 		{
-			name: "with retry delay in nanoseconds",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", `HTTP 429 error with {"retryAfterNS": 5000000000}`),
-			},
+			name:     "retry-after in seconds",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Header: http.Header{"Retry-After": []string{"7"}}},
+			expected: 7 * time.Second,
+		},
+		{
+			name:     "retry-after as HTTP date",
+			err:      &gqlerrors.RequestError{StatusCode: 503, Header: http.Header{"Retry-After": []string{now.Add(90 * time.Second).Format(http.TimeFormat)}}},
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "retry-after date in the past",
+			err:      &gqlerrors.RequestError{StatusCode: 503, Header: http.Header{"Retry-After": []string{now.Add(-time.Minute).Format(http.TimeFormat)}}},
+			expected: 0,
+		},
+		{
+			name:     "rate limit reset in seconds",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Header: http.Header{"Ratelimit-Reset": []string{"12"}}},
+			expected: 12 * time.Second,
+		},
+		{
+			name:     "rate limit reset as Unix time",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Header: http.Header{"X-Ratelimit-Reset": []string{strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)}}},
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "rate limit reset ignored when not rate limited",
+			err:      &gqlerrors.RequestError{StatusCode: 503, Header: http.Header{"X-Ratelimit-Reset": []string{"12"}}},
+			expected: 0,
+		},
+		{
+			name:     "retry delay in nanoseconds",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Body: `{"retryAfterNS": 5000000000}`},
 			expected: 5 * time.Second,
 		},
 		{
-			name: "invalid retry delay",
-			diags: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Error", "Some error with retry delay: invalid"),
-			},
+			name:     "retry-after takes precedence",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Header: http.Header{"Retry-After": []string{"2"}}, Body: `{"retryAfterNS": 5000000000}`},
+			expected: 2 * time.Second,
+		},
+		{
+			name:     "invalid retry delay",
+			err:      &gqlerrors.RequestError{StatusCode: 429, Header: http.Header{"Retry-After": []string{"soon"}}, Body: `{"retryAfterNS": "invalid"}`},
 			expected: 0,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseRetryDelay(tt.diags)
+			result := parseRetryDelay(tt.err, now)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestExecuteGraphQLRequestFramework_CancelledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, diags := executeGraphQLRequestFramework(ctx, "query { ok }", nil, config)
+	require.True(t, diags.HasError())
+	assert.Less(t, time.Since(start), 5*time.Second, "backoff must stop when the context is cancelled")
+
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary())
+	}
+	assert.Contains(t, summaries, "Request Cancelled")
+}
+
 func TestExtractPaginatedData(t *testing.T) {
 	tests := []struct {
 		name            string
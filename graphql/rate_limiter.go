@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// adaptiveThrottleFraction is the share of the server's request budget
+	// below which adaptive throttling starts spreading out requests.
+	adaptiveThrottleFraction = 0.1
+	// adaptiveThrottleRemaining is used instead when the server does not
+	// report its limit.
+	adaptiveThrottleRemaining = 10
+	// adaptiveDefaultWindow is assumed when the server does not report when
+	// its rate limit resets.
+	adaptiveDefaultWindow = time.Minute
+)
+
+// requestRateLimiter paces the requests of one provider instance. In adaptive
+// mode it also slows down as the server reports that its rate limit is
+// nearly exhausted, and pauses entirely until the limit resets once it is.
+type requestRateLimiter struct {
+	limiter   *rate.Limiter
+	baseLimit rate.Limit
+	adaptive  bool
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// newRequestRateLimiter returns a limiter allowing one request per delay with
+// the given burst. It returns nil, meaning unlimited, when there is no delay
+// and adaptive throttling is disabled.
+func newRequestRateLimiter(delay time.Duration, burst int, adaptive bool) *requestRateLimiter {
+	if delay <= 0 && !adaptive {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	limit := rate.Inf
+	if delay > 0 {
+		limit = rate.Every(delay)
+	}
+	return &requestRateLimiter{
+		limiter:   rate.NewLimiter(limit, burst),
+		baseLimit: limit,
+		adaptive:  adaptive,
+	}
+}
+
+// Wait blocks until a request may be sent or the context is cancelled.
+func (l *requestRateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	pause := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+
+	if pause > 0 {
+		tflog.Debug(ctx, "Rate limit exhausted, waiting for it to reset", map[string]any{
+			"pause": pause,
+		})
+		if err := sleepContext(ctx, pause); err != nil {
+			return err
+		}
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// Observe adjusts the request rate to the remaining budget reported by the
+// X-RateLimit-Remaining (or RateLimit-Remaining) response header.
+func (l *requestRateLimiter) Observe(ctx context.Context, header http.Header, now time.Time) {
+	if l == nil || !l.adaptive {
+		return
+	}
+
+	remaining, ok := parseRateLimitHeader(header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if !ok {
+		return
+	}
+	limit, hasLimit := parseRateLimitHeader(header, "X-RateLimit-Limit", "RateLimit-Limit")
+
+	var reset time.Duration
+	for _, name := range []string{"X-RateLimit-Reset", "RateLimit-Reset", "X-RateLimit-Reset-After"} {
+		if reset = parseRateLimitReset(header.Get(name), now); reset > 0 {
+			break
+		}
+	}
+
+	low := remaining <= adaptiveThrottleRemaining
+	if hasLimit {
+		low = float64(remaining) <= float64(limit)*adaptiveThrottleFraction
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case remaining <= 0:
+		if reset <= 0 {
+			reset = adaptiveDefaultWindow
+		}
+		l.pausedUntil = now.Add(reset)
+		tflog.Debug(ctx, "Server rate limit exhausted, pausing requests", map[string]any{
+			"reset": reset,
+		})
+	case low:
+		if reset <= 0 {
+			reset = adaptiveDefaultWindow
+		}
+		// Spread the remaining requests evenly over the rest of the window
+		spread := rate.Every(reset / time.Duration(remaining+1))
+		if spread < l.baseLimit {
+			l.limiter.SetLimitAt(now, spread)
+			tflog.Debug(ctx, "Server rate limit nearly exhausted, slowing down", map[string]any{
+				"remaining": remaining,
+				"reset":     reset,
+				"interval":  reset / time.Duration(remaining+1),
+			})
+		}
+	default:
+		l.limiter.SetLimitAt(now, l.baseLimit)
+	}
+}
+
+// parseRateLimitHeader returns the integer value of the first of the named
+// headers that is present.
+func parseRateLimitHeader(header http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		// Some servers send one value per policy, e.g. "5, 100;w=60"
+		value, _, _ = strings.Cut(value, ",")
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		return number, true
+	}
+	return 0, false
+}
+
+// initializeRateLimiters creates the query and mutation rate limiters of this
+// provider instance from the configured delays.
+func (c *graphqlProviderConfig) initializeRateLimiters(burst int, adaptive bool) {
+	c.QueryRateLimiter = newRequestRateLimiter(c.QueryRateLimitDelay, burst, adaptive)
+	c.MutationRateLimiter = newRequestRateLimiter(c.MutationRateLimitDelay, burst, adaptive)
+}
+
+// rateLimiter returns the limiter for queries or mutations.
+func (c *graphqlProviderConfig) rateLimiter(isMutation bool) *requestRateLimiter {
+	if isMutation {
+		return c.MutationRateLimiter
+	}
+	return c.QueryRateLimiter
+}
@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestInitializeRateLimiters(t *testing.T) {
+	tests := []struct {
+		name           string
+		queryDelay     time.Duration
+		mutationDelay  time.Duration
+		adaptive       bool
+		expectQuery    bool
+		expectMutation bool
+	}{
+		{
+			name:           "both delays set",
+			queryDelay:     100 * time.Millisecond,
+			mutationDelay:  200 * time.Millisecond,
+			expectQuery:    true,
+			expectMutation: true,
+		},
+		{
+			name:           "only query delay",
+			queryDelay:     100 * time.Millisecond,
+			mutationDelay:  0,
+			expectQuery:    true,
+			expectMutation: false,
+		},
+		{
+			name:           "only mutation delay",
+			queryDelay:     0,
+			mutationDelay:  200 * time.Millisecond,
+			expectQuery:    false,
+			expectMutation: true,
+		},
+		{
+			name:           "no delays",
+			queryDelay:     0,
+			mutationDelay:  0,
+			expectQuery:    false,
+			expectMutation: false,
+		},
+		{
+			name:           "adaptive without delays",
+			queryDelay:     0,
+			mutationDelay:  0,
+			adaptive:       true,
+			expectQuery:    true,
+			expectMutation: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &graphqlProviderConfig{
+				QueryRateLimitDelay:    tt.queryDelay,
+				MutationRateLimitDelay: tt.mutationDelay,
+			}
+			config.initializeRateLimiters(1, tt.adaptive)
+
+			if tt.expectQuery {
+				assert.NotNil(t, config.QueryRateLimiter)
+			} else {
+				assert.Nil(t, config.QueryRateLimiter)
+			}
+
+			if tt.expectMutation {
+				assert.NotNil(t, config.MutationRateLimiter)
+			} else {
+				assert.Nil(t, config.MutationRateLimiter)
+			}
+		})
+	}
+}
+
+func TestRateLimiters_PerProviderInstance(t *testing.T) {
+	slow := &graphqlProviderConfig{QueryRateLimitDelay: time.Hour}
+	slow.initializeRateLimiters(1, false)
+	fast := &graphqlProviderConfig{QueryRateLimitDelay: time.Millisecond}
+	fast.initializeRateLimiters(1, false)
+
+	// Exhaust the slow instance's budget; the fast instance must be unaffected
+	require.NoError(t, slow.rateLimiter(false).Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, fast.rateLimiter(false).Wait(ctx))
+	}
+	assert.Error(t, slow.rateLimiter(false).Wait(ctx), "the slow instance keeps its own limit")
+}
+
+func TestRequestRateLimiter_Burst(t *testing.T) {
+	limiter := newRequestRateLimiter(time.Hour, 3, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(ctx))
+	}
+	assert.Error(t, limiter.Wait(ctx), "requests beyond the burst wait for the delay")
+}
+
+func TestRequestRateLimiter_Adaptive(t *testing.T) {
+	now := time.Now()
+
+	t.Run("slows down when the budget runs low", func(t *testing.T) {
+		limiter := newRequestRateLimiter(10*time.Millisecond, 1, true)
+		limiter.Observe(context.Background(), http.Header{
+			"X-Ratelimit-Limit":     []string{"100"},
+			"X-Ratelimit-Remaining": []string{"4"},
+			"X-Ratelimit-Reset":     []string{"10"},
+		}, now)
+		assert.Equal(t, rate.Every(2*time.Second), limiter.limiter.Limit())
+
+		// Back to the configured rate once the budget recovers
+		limiter.Observe(context.Background(), http.Header{
+			"X-Ratelimit-Limit":     []string{"100"},
+			"X-Ratelimit-Remaining": []string{"90"},
+		}, now)
+		assert.Equal(t, rate.Every(10*time.Millisecond), limiter.limiter.Limit())
+	})
+
+	t.Run("pauses until the limit resets", func(t *testing.T) {
+		limiter := newRequestRateLimiter(0, 1, true)
+		limiter.Observe(context.Background(), http.Header{
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{strconv.FormatInt(now.Add(time.Hour).Unix(), 10)},
+		}, now)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.Error(t, limiter.Wait(ctx))
+	})
+
+	t.Run("ignored unless enabled", func(t *testing.T) {
+		limiter := newRequestRateLimiter(10*time.Millisecond, 1, false)
+		limiter.Observe(context.Background(), http.Header{"X-Ratelimit-Remaining": []string{"0"}}, now)
+		require.NoError(t, limiter.Wait(context.Background()))
+		assert.Equal(t, rate.Every(10*time.Millisecond), limiter.limiter.Limit())
+	})
+}
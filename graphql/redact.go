@@ -0,0 +1,305 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedValue replaces sensitive values in logs. It matches the replacement
+// string used by tflog masking so redacted output looks the same everywhere.
+const redactedValue = "***"
+
+// minMaskedValueLength is the shortest value that is masked wherever it
+// appears in log output. Shorter values (e.g. "1" or "true") would mangle
+// unrelated log fields; they are still redacted at their variable paths.
+const minMaskedValueLength = 4
+
+// sensitiveHeaderNames are request headers whose values are always redacted.
+var sensitiveHeaderNames = map[string]bool{
+	"authorization":        true,
+	"proxy-authorization":  true,
+	"cookie":               true,
+	"set-cookie":           true,
+	"x-api-key":            true,
+	"api-key":              true,
+	"apikey":               true,
+	"x-auth-token":         true,
+	"x-csrf-token":         true,
+	"x-xsrf-token":         true,
+	"x-amz-security-token": true,
+}
+
+// sensitiveHeaderFragments mark custom headers that likely carry credentials.
+var sensitiveHeaderFragments = []string{"token", "secret", "password", "api-key", "apikey", "session"}
+
+// sensitiveLogPatterns mask credentials embedded in free-form log values, such
+// as bearer tokens in error bodies and well-known secret fields in JSON.
+var sensitiveLogPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`(?i)"(access_?token|refresh_?token|id_?token|client_?secret|password|passwd|secret|api_?key)"\s*:\s*"[^"]*"`),
+}
+
+// isSensitiveHeader reports whether the value of a header must not be logged.
+func isSensitiveHeader(name string) bool {
+	lowerName := strings.ToLower(name)
+	if sensitiveHeaderNames[lowerName] {
+		return true
+	}
+	for _, fragment := range sensitiveHeaderFragments {
+		if strings.Contains(lowerName, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders returns a copy of the headers with sensitive values redacted.
+func redactHeaders(headers map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(headers))
+	for k, v := range headers {
+		if isSensitiveHeader(k) {
+			redacted[k] = redactedValue
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// parseSensitiveVariablePaths splits dot-separated variable paths such as
+// "input.password" into their segments. A "*" segment matches any key.
+func parseSensitiveVariablePaths(paths []string) [][]string {
+	parsed := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		parsed = append(parsed, strings.Split(p, "."))
+	}
+	return parsed
+}
+
+// redactVariables returns a deep copy of the variables with every value at a
+// sensitive path replaced. Lists are traversed transparently, so "items.token"
+// matches the token of every element of items.
+func redactVariables(variables map[string]interface{}, paths [][]string) map[string]interface{} {
+	if variables == nil || len(paths) == 0 {
+		return variables
+	}
+
+	redacted, _ := redactValue(deepCopyJSONValue(variables), paths).(map[string]interface{})
+	return redacted
+}
+
+func redactValue(value interface{}, paths [][]string) interface{} {
+	for _, p := range paths {
+		value = redactPath(value, p)
+	}
+	return value
+}
+
+func redactPath(value interface{}, segments []string) interface{} {
+	if len(segments) == 0 {
+		return redactedValue
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if segments[0] == "*" || segments[0] == k {
+				v[k] = redactPath(child, segments[1:])
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactPath(child, segments)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// isSensitivePath reports whether a variable path, given as object keys
+// without list indices, is at or below one of the sensitive paths.
+func isSensitivePath(variablePath []string, paths [][]string) bool {
+	for _, p := range paths {
+		if len(variablePath) < len(p) {
+			continue
+		}
+		matches := true
+		for i, segment := range p {
+			if segment != "*" && segment != variablePath[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// collectSensitiveValues returns the scalar values found at the sensitive paths.
+func collectSensitiveValues(value interface{}, segments []string, values []string) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if len(segments) == 0 || segments[0] == "*" || segments[0] == k {
+				rest := segments
+				if len(segments) > 0 {
+					rest = segments[1:]
+				}
+				values = collectSensitiveValues(child, rest, values)
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			values = collectSensitiveValues(child, segments, values)
+		}
+	case nil:
+	default:
+		if len(segments) == 0 {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	}
+	return values
+}
+
+// deepCopyJSONValue copies a value decoded from JSON so it can be modified
+// without affecting the original.
+func deepCopyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			copied[k] = deepCopyJSONValue(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = deepCopyJSONValue(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// withMaskedValues masks the given values wherever they appear in log
+// messages and string log fields.
+func withMaskedValues(ctx context.Context, values ...string) context.Context {
+	var masked []string
+	for _, value := range values {
+		if len(value) >= minMaskedValueLength {
+			masked = append(masked, value)
+		}
+	}
+	if len(masked) == 0 {
+		return ctx
+	}
+
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, masked...)
+	return tflog.MaskMessageStrings(ctx, masked...)
+}
+
+// logValue encodes a structured log field as JSON so it is covered by the
+// string masking applied to the logging context.
+func logValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// logContext returns a context whose log output masks provider credentials
+// and the values found at sensitive_variable_paths in the given variables.
+func (c *graphqlProviderConfig) logContext(ctx context.Context, variables ...map[string]interface{}) context.Context {
+	ctx = tflog.MaskAllFieldValuesRegexes(ctx, sensitiveLogPatterns...)
+	ctx = tflog.MaskMessageRegexes(ctx, sensitiveLogPatterns...)
+
+	if c == nil {
+		return ctx
+	}
+
+	var secrets []string
+	for k, v := range c.RequestHeaders {
+		if isSensitiveHeader(k) {
+			secrets = append(secrets, fmt.Sprintf("%v", v))
+		}
+	}
+	if c.TokenSource != nil {
+		secrets = append(secrets, c.TokenSource.cachedToken())
+	}
+	if c.TokenFile != nil {
+		secrets = append(secrets, strings.TrimSpace(string(c.TokenFile.cached())))
+	}
+	if c.HeadersFile != nil {
+		// The file holds rotated credentials, often under header names that
+		// do not look sensitive, so every value is masked
+		var fileHeaders map[string]string
+		if json.Unmarshal(c.HeadersFile.cached(), &fileHeaders) == nil {
+			for _, v := range fileHeaders {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+	if c.SessionSource != nil {
+		secrets = append(secrets, c.SessionSource.cachedToken())
+		secrets = append(secrets, c.sessionCookieValues()...)
+	}
+	for _, vars := range variables {
+		for _, p := range c.SensitiveVariablePaths {
+			secrets = collectSensitiveValues(vars, p, secrets)
+		}
+	}
+
+	return withMaskedValues(ctx, secrets...)
+}
+
+// sessionCookieValues returns the values of the session cookies sent to the
+// GraphQL endpoint.
+func (c *graphqlProviderConfig) sessionCookieValues() []string {
+	if c.HTTPClient == nil || c.HTTPClient.Jar == nil {
+		return nil
+	}
+	endpoint, err := url.Parse(c.GQLServerUrl)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	for _, cookie := range c.HTTPClient.Jar.Cookies(endpoint) {
+		values = append(values, cookie.Value)
+	}
+	return values
+}
+
+// isSensitiveVariable reports whether the value at a variable path is
+// covered by sensitive_variable_paths.
+func (c *graphqlProviderConfig) isSensitiveVariable(variablePath []string) bool {
+	if c == nil {
+		return false
+	}
+	return isSensitivePath(variablePath, c.SensitiveVariablePaths)
+}
+
+// redactVariables returns the variables with the values at
+// sensitive_variable_paths redacted, for logging.
+func (c *graphqlProviderConfig) redactVariables(variables map[string]interface{}) map[string]interface{} {
+	if c == nil {
+		return variables
+	}
+	return redactVariables(variables, c.SensitiveVariablePaths)
+}
@@ -0,0 +1,256 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	headers := map[string]interface{}{
+		"Authorization":   "Bearer abc123",
+		"X-Api-Key":       "key",
+		"X-Session-Id":    "session",
+		"X-Custom-Secret": "secret",
+		"Content-Type":    "application/json",
+	}
+
+	redacted := redactHeaders(headers)
+
+	assert.Equal(t, redactedValue, redacted["Authorization"])
+	assert.Equal(t, redactedValue, redacted["X-Api-Key"])
+	assert.Equal(t, redactedValue, redacted["X-Session-Id"])
+	assert.Equal(t, redactedValue, redacted["X-Custom-Secret"])
+	assert.Equal(t, "application/json", redacted["Content-Type"])
+	assert.Equal(t, "Bearer abc123", headers["Authorization"], "original headers must not be modified")
+}
+
+func TestRedactVariables(t *testing.T) {
+	tests := []struct {
+		name      string
+		paths     []string
+		variables map[string]interface{}
+		expected  map[string]interface{}
+	}{
+		{
+			name:      "no paths",
+			variables: map[string]interface{}{"password": "hunter2"},
+			expected:  map[string]interface{}{"password": "hunter2"},
+		},
+		{
+			name:  "nested path",
+			paths: []string{"input.password"},
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{"name": "svc", "password": "hunter2"},
+			},
+			expected: map[string]interface{}{
+				"input": map[string]interface{}{"name": "svc", "password": redactedValue},
+			},
+		},
+		{
+			name:  "wildcard and lists",
+			paths: []string{"*.credentials.token"},
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{
+					"credentials": []interface{}{
+						map[string]interface{}{"token": "t1", "kind": "api"},
+						map[string]interface{}{"token": "t2", "kind": "api"},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"input": map[string]interface{}{
+					"credentials": []interface{}{
+						map[string]interface{}{"token": redactedValue, "kind": "api"},
+						map[string]interface{}{"token": redactedValue, "kind": "api"},
+					},
+				},
+			},
+		},
+		{
+			name:  "whole object",
+			paths: []string{"input.secrets"},
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{"secrets": map[string]interface{}{"a": "b"}},
+			},
+			expected: map[string]interface{}{
+				"input": map[string]interface{}{"secrets": redactedValue},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := logValue(tt.variables)
+			result := redactVariables(tt.variables, parseSensitiveVariablePaths(tt.paths))
+
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, original, logValue(tt.variables), "original variables must not be modified")
+		})
+	}
+}
+
+func TestLogContext_MasksSecrets(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	config := &graphqlProviderConfig{
+		RequestHeaders:         map[string]interface{}{"X-Api-Key": "static-api-key", "X-Tenant": "acme"},
+		SensitiveVariablePaths: parseSensitiveVariablePaths([]string{"input.password"}),
+	}
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{"name": "svc", "password": "hunter2-password"},
+	}
+
+	ctx = config.logContext(ctx, variables)
+	tflog.Debug(ctx, "request", map[string]any{
+		"variablesJSON": `{"input":{"name":"svc","password":"hunter2-password"}}`,
+		"responseBody":  `{"error":"key static-api-key rejected","hint":"Bearer eyJhbGciOi.payload.sig"}`,
+		"variables":     config.redactVariables(variables),
+		"headers":       redactHeaders(config.RequestHeaders),
+	})
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	logged := logValue(entries[0])
+	assert.NotContains(t, logged, "hunter2-password")
+	assert.NotContains(t, logged, "static-api-key")
+	assert.NotContains(t, logged, "eyJhbGciOi")
+	assert.Contains(t, logged, "acme")
+	assert.Contains(t, logged, "svc")
+}
+
+func TestLogContext_MasksFileAndSessionCredentials(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("file-bearer-token\n"), 0o600))
+	headersPath := filepath.Join(dir, "headers.json")
+	require.NoError(t, os.WriteFile(headersPath, []byte(`{"X-Tenant-Key":"file-header-value"}`), 0o600))
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	endpoint, err := url.Parse("https://api.example.com/graphql")
+	require.NoError(t, err)
+	jar.SetCookies(endpoint, []*http.Cookie{{Name: "sessionid", Value: "session-cookie-value"}})
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl: endpoint.String(),
+		HTTPClient:   &http.Client{Jar: jar},
+		TokenFile:    newCachedFile(tokenPath),
+		HeadersFile:  newCachedFile(headersPath),
+		SessionSource: newTokenSource(func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			return "csrf-token-value", time.Time{}, nil
+		}),
+	}
+	_, err = config.TokenFile.ReadToken()
+	require.NoError(t, err)
+	_, err = config.HeadersFile.ReadHeaders()
+	require.NoError(t, err)
+	_, diags := config.SessionSource.Token(context.Background())
+	require.False(t, diags.HasError())
+
+	var output bytes.Buffer
+	ctx := config.logContext(tflogtest.RootLogger(context.Background(), &output))
+	tflog.Debug(ctx, "response", map[string]any{
+		"responseBody": `{"echo":"file-bearer-token file-header-value csrf-token-value session-cookie-value"}`,
+	})
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	logged := logValue(entries[0])
+	assert.NotContains(t, logged, "file-bearer-token")
+	assert.NotContains(t, logged, "file-header-value")
+	assert.NotContains(t, logged, "csrf-token-value")
+	assert.NotContains(t, logged, "session-cookie-value")
+}
+
+func TestSendGraphQLRequest_RedactsLoggedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"login":true}}`))
+	}))
+	defer server.Close()
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:           server.URL,
+		RequestHeaders:         map[string]interface{}{},
+		SensitiveVariablePaths: parseSensitiveVariablePaths([]string{"input.credential"}),
+	}
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{"name": "svc", "credential": "s3cr3t-credential"},
+	}
+
+	// Without a masking logging context, only path redaction applies
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	_, _, _, diags := sendGraphQLRequest(ctx, newGraphQLRequest(ctx, "mutation($input: LoginInput!) { login(input: $input) }", variables), config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+
+	assert.NotContains(t, output.String(), "s3cr3t-credential")
+	assert.Contains(t, output.String(), "svc")
+}
+
+func TestComputeMutationVariableKeys_DoesNotLogValues(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	values, err := computeMutationVariableKeys(ctx, map[string]interface{}{"token": "login.token"}, `{"data":{"login":{"token":"super-secret-token"}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "super-secret-token", values["token"])
+
+	assert.NotContains(t, output.String(), "super-secret-token")
+}
+
+func TestReplaceSelfReferences_MasksSensitiveComputedValues(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	r := &GraphqlMutationResource{config: &graphqlProviderConfig{
+		SensitiveVariablePaths: parseSensitiveVariablePaths([]string{"input.credential"}),
+	}}
+	data := &GraphqlMutationResourceModel{
+		ComputedValues: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"credential": types.StringValue("server-issued-credential"),
+			"name":       types.StringValue("svc-name"),
+		}),
+	}
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{"credential": "self.credential", "name": "self.name"},
+	}
+
+	result := r.replaceSelfReferences(ctx, data, variables)
+	assert.Equal(t, map[string]interface{}{
+		"input": map[string]interface{}{"credential": "server-issued-credential", "name": "svc-name"},
+	}, result)
+
+	assert.NotContains(t, output.String(), "server-issued-credential")
+	assert.Contains(t, output.String(), "svc-name")
+}
+
+func TestIsSensitivePath(t *testing.T) {
+	paths := parseSensitiveVariablePaths([]string{"input.credential", "*.secret"})
+
+	assert.True(t, isSensitivePath([]string{"input", "credential"}, paths))
+	assert.True(t, isSensitivePath([]string{"input", "credential", "value"}, paths), "values below a sensitive path are sensitive")
+	assert.True(t, isSensitivePath([]string{"other", "secret"}, paths))
+	assert.False(t, isSensitivePath([]string{"input"}, paths))
+	assert.False(t, isSensitivePath([]string{"input", "name"}, paths))
+}
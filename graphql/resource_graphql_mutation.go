@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -47,6 +48,9 @@ type GraphqlMutationResourceModel struct {
 	ExistingHash                     types.String  `tfsdk:"existing_hash"`
 	CurrentRemoteState               types.String  `tfsdk:"current_remote_state"`
 	Id                               types.String  `tfsdk:"id"`
+	LockKey                          types.String  `tfsdk:"lock_key"`
+	FileUploads                      types.Map     `tfsdk:"file_uploads"`
+	FileUploadsSHA256                types.String  `tfsdk:"file_uploads_sha256"`
 }
 
 // Add this helper function at file scope:
@@ -147,6 +151,19 @@ func (r *GraphqlMutationResource) Schema(ctx context.Context, req resource.Schem
 				Optional:    true,
 				Description: "If true, all updates will first delete the resource and recreate it.",
 			},
+			"lock_key": schema.StringAttribute{
+				Optional:    true,
+				Description: "Resources with the same lock key run their create, update and delete mutations one at a time, e.g. for APIs that reject concurrent changes to the same parent object.",
+			},
+			"file_uploads": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Local files to send as `Upload` scalars in the create and update mutations, keyed by the dot-separated path of the variable (e.g., 'input.certificate' or 'input.files.0'). Requests are sent as multipart requests following the GraphQL multipart request specification, and updates send the full mutation_variables so the paths apply.",
+			},
+			"file_uploads_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "A hash of the contents of the files in file_uploads, so editing a file updates the resource.",
+			},
 			"enable_remote_state_verification": schema.BoolAttribute{
 				Optional:    true,
 				Description: "A pre v2.4.0 backward-compatibility flag. Set to false to disable resource remote state verification during reads. Defaults to true.",
@@ -219,6 +236,15 @@ func (r *GraphqlMutationResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 
+	ctx = r.logContext(ctx, &data)
+
+	unlock, diags := r.lock(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
 	// Validate that either compute_mutation_keys is provided OR compute_from_read is true
 	hasComputeMutationKeys := !data.ComputeMutationKeys.IsNull() && !data.ComputeMutationKeys.IsUnknown()
 	hasComputeFromRead := !data.ComputeFromRead.IsNull() && !data.ComputeFromRead.IsUnknown() && data.ComputeFromRead.ValueBool()
@@ -244,8 +270,14 @@ func (r *GraphqlMutationResource) Create(ctx context.Context, req resource.Creat
 		}
 	}
 
+	uploads, diags := r.fileUploads(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Execute create operation
-	createBytes, diags := r.executeCreateHook(ctx, &data, r.config)
+	createBytes, diags := r.executeCreateHook(withFileUploads(ctx, uploads), &data, r.config)
 	if diags.HasError() {
 		resp.Diagnostics.Append(diags...)
 		return
@@ -322,6 +354,8 @@ func (r *GraphqlMutationResource) Read(ctx context.Context, req resource.ReadReq
 		return
 	}
 
+	ctx = r.logContext(ctx, &data)
+
 	// CRITICAL: Preserve the original mutation_variables from the state
 	// This ensures we don't modify the user's intended configuration
 	originalMutationVariables := data.MutationVariables
@@ -407,9 +441,9 @@ func (r *GraphqlMutationResource) Read(ctx context.Context, req resource.ReadReq
 							hasDrift := len(changedFields) > 0
 
 							tflog.Debug(ctx, "State comparison in Read", map[string]any{
-								"desiredFields":      desiredFields,
-								"currentRemoteState": currentRemoteState,
-								"changedFields":      changedFields,
+								"desiredFields":      logValue(desiredFields),
+								"currentRemoteState": logValue(currentRemoteState),
+								"changedFields":      logValue(changedFields),
 								"hasDrift":           hasDrift,
 							})
 
@@ -419,7 +453,7 @@ func (r *GraphqlMutationResource) Read(ctx context.Context, req resource.ReadReq
 
 							if hasDrift {
 								tflog.Info(ctx, "DRIFT DETECTED - Resource state differs from desired configuration", map[string]any{
-									"changedFields": changedFields,
+									"changedFields": logValue(changedFields),
 								})
 
 								// CRITICAL: Signal drift to Terraform by modifying the mutation_variables
@@ -452,7 +486,7 @@ func (r *GraphqlMutationResource) Read(ctx context.Context, req resource.ReadReq
 									updateVarsBytes, _ := json.Marshal(normalizeForJSON(updateVars))
 									data.ComputedUpdateOperationVariables = types.StringValue(string(updateVarsBytes))
 									tflog.Info(ctx, "Set ComputedUpdateOperationVariables for patch update (deep diff)", map[string]any{
-										"updateVars": updateVars,
+										"updateVars": logValue(updateVars),
 									})
 								} else {
 									// No patch, update input directly
@@ -462,7 +496,7 @@ func (r *GraphqlMutationResource) Read(ctx context.Context, req resource.ReadReq
 									updateVarsBytes, _ := json.Marshal(normalizeForJSON(updateVars))
 									data.ComputedUpdateOperationVariables = types.StringValue(string(updateVarsBytes))
 									tflog.Info(ctx, "Set ComputedUpdateOperationVariables for direct update", map[string]any{
-										"updateVars": updateVars,
+										"updateVars": logValue(updateVars),
 									})
 								}
 							} else {
@@ -481,7 +515,7 @@ func (r *GraphqlMutationResource) Read(ctx context.Context, req resource.ReadReq
 	tflog.Debug(ctx, "Final state before commit", map[string]any{
 		"currentRemoteState": data.CurrentRemoteState.ValueString(),
 		"queryResponse":      data.QueryResponse.ValueString(),
-		"computedValues":     data.ComputedValues,
+		"computedValues":     data.ComputedValues.String(),
 		"success":            true,
 	})
 
@@ -507,6 +541,15 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
+	ctx = r.logContext(ctx, &data, &state)
+
+	unlock, diags := r.lock(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
 	// Validate that either compute_mutation_keys is provided OR compute_from_read is true
 	hasComputeMutationKeys := !data.ComputeMutationKeys.IsNull() && !data.ComputeMutationKeys.IsUnknown()
 	hasComputeFromRead := !data.ComputeFromRead.IsNull() && !data.ComputeFromRead.IsUnknown() && data.ComputeFromRead.ValueBool()
@@ -519,6 +562,13 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
+	uploads, diags := r.fileUploads(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	uploadCtx := withFileUploads(ctx, uploads)
+
 	// CRITICAL: Preserve the original mutation_variables from the plan
 	// This ensures we don't modify the user's intended configuration
 	originalMutationVariables := data.MutationVariables
@@ -540,7 +590,7 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 		}
 
 		// Create the resource again
-		_, createDiags := r.executeCreateHook(ctx, &data, r.config)
+		_, createDiags := r.executeCreateHook(uploadCtx, &data, r.config)
 		if createDiags.HasError() {
 			resp.Diagnostics.Append(createDiags...)
 			return
@@ -586,15 +636,15 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 								hasDrift := len(changedFields) > 0
 
 								tflog.Debug(ctx, "Drift detection in Update", map[string]any{
-									"currentRemoteState": currentRemoteState,
-									"desiredFields":      desiredFields,
-									"changedFields":      changedFields,
+									"currentRemoteState": logValue(currentRemoteState),
+									"desiredFields":      logValue(desiredFields),
+									"changedFields":      logValue(changedFields),
 									"hasDrift":           hasDrift,
 								})
 
 								if hasDrift {
 									tflog.Info(ctx, "DRIFT DETECTED in Update - Resource state differs from desired configuration", map[string]any{
-										"changedFields": changedFields,
+										"changedFields": logValue(changedFields),
 									})
 								} else {
 									tflog.Debug(ctx, "No drift detected - resource state matches desired configuration")
@@ -614,6 +664,12 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 			return
 		}
 
+		// File upload paths refer to mutation_variables, so send them in full
+		// rather than as a patch
+		if len(uploads) > 0 {
+			data.ComputedUpdateOperationVariables = types.StringValue("")
+		}
+
 		// Log the computed update variables for debugging
 		if !data.ComputedUpdateOperationVariables.IsNull() && !data.ComputedUpdateOperationVariables.IsUnknown() {
 			tflog.Debug(ctx, "Computed update variables", map[string]any{
@@ -661,7 +717,7 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 				})
 			}
 			// Execute update operation using computed update variables (patch)
-			_, updateDiags := r.executeUpdateHook(ctx, &data, r.config)
+			_, updateDiags := r.executeUpdateHook(uploadCtx, &data, r.config)
 			if updateDiags.HasError() {
 				resp.Diagnostics.Append(updateDiags...)
 				return
@@ -687,7 +743,7 @@ func (r *GraphqlMutationResource) Update(ctx context.Context, req resource.Updat
 				data.CurrentRemoteState = types.StringValue(string(currentStateBytes))
 
 				tflog.Debug(ctx, "Set CurrentRemoteState after update", map[string]any{
-					"currentRemoteState": currentRemoteState,
+					"currentRemoteState": logValue(currentRemoteState),
 					"currentStateBytes":  string(currentStateBytes),
 				})
 			}
@@ -715,8 +771,17 @@ func (r *GraphqlMutationResource) Delete(ctx context.Context, req resource.Delet
 		return
 	}
 
+	ctx = r.logContext(ctx, &data)
+
+	unlock, diags := r.lock(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
 	// Execute delete operation
-	diags := r.executeDeleteHook(ctx, &data, r.config)
+	diags = r.executeDeleteHook(ctx, &data, r.config)
 	if diags.HasError() {
 		resp.Diagnostics.Append(diags...)
 		return
@@ -735,6 +800,80 @@ func (r *GraphqlMutationResource) ImportState(ctx context.Context, req resource.
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// lock acquires the resource's lock_key, if set, so resources sharing a key
+// are changed one at a time. The returned function releases it.
+func (r *GraphqlMutationResource) lock(ctx context.Context, data *GraphqlMutationResourceModel) (func(), diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !isSet(data.LockKey) {
+		return func() {}, diags
+	}
+
+	key := data.LockKey.ValueString()
+	tflog.Debug(ctx, "Waiting for lock", map[string]any{"lockKey": key})
+	unlock, err := mutationLocks.Lock(ctx, key)
+	if err != nil {
+		diags.AddError("Lock Error", fmt.Sprintf("failed to acquire lock %q: %v", key, err))
+		return nil, diags
+	}
+	tflog.Debug(ctx, "Acquired lock", map[string]any{"lockKey": key})
+	return unlock, diags
+}
+
+// ModifyPlan records a hash of the files in file_uploads, so editing a file
+// updates the resource even though its path is unchanged.
+func (r *GraphqlMutationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to do when the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var fileUploads types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("file_uploads"), &fileUploads)...)
+	if resp.Diagnostics.HasError() || fileUploads.IsUnknown() {
+		return
+	}
+
+	uploads, diags := expandFileUploads(ctx, fileUploads)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uploadsHash := types.StringNull()
+	if len(uploads) > 0 {
+		sum, err := fileUploadsHash(uploads)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("file_uploads"), "Unable to Read File Upload", err.Error())
+			return
+		}
+		uploadsHash = types.StringValue(sum)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("file_uploads_sha256"), uploadsHash)...)
+}
+
+// fileUploads returns the files to upload with the create and update
+// mutations, hashing them if their paths were not known at plan time.
+func (r *GraphqlMutationResource) fileUploads(ctx context.Context, data *GraphqlMutationResourceModel) ([]fileUpload, diag.Diagnostics) {
+	uploads, diags := expandFileUploads(ctx, data.FileUploads)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if data.FileUploadsSHA256.IsUnknown() {
+		data.FileUploadsSHA256 = types.StringNull()
+		if len(uploads) > 0 {
+			sum, err := fileUploadsHash(uploads)
+			if err != nil {
+				diags.AddAttributeError(path.Root("file_uploads"), "Unable to Read File Upload", err.Error())
+				return nil, diags
+			}
+			data.FileUploadsSHA256 = types.StringValue(sum)
+		}
+	}
+	return uploads, diags
+}
+
 // Helper methods for CRUD operations
 func (r *GraphqlMutationResource) executeCreateHook(ctx context.Context, data *GraphqlMutationResourceModel, config *graphqlProviderConfig) ([]byte, diag.Diagnostics) {
 	var diags diag.Diagnostics
@@ -834,7 +973,7 @@ func (r *GraphqlMutationResource) executeCreateHook(ctx context.Context, data *G
 		"keysToUse": keysToUse,
 	})
 
-	if err := r.computeMutationVariables(string(resBytes), data, keysToUse); err != nil {
+	if err := r.computeMutationVariables(ctx, string(resBytes), data, keysToUse); err != nil {
 		diags.AddError("Computation Error", fmt.Sprintf("Unable to compute keys from create response: %s", err))
 		return nil, diags
 	}
@@ -1255,7 +1394,7 @@ func (r *GraphqlMutationResource) readResource(ctx context.Context, data *Graphq
 	}
 
 	// Compute mutation variables
-	if err := r.computeMutationVariables(string(resBytes), data, keysToUse); err != nil {
+	if err := r.computeMutationVariables(ctx, string(resBytes), data, keysToUse); err != nil {
 		// Check if the error indicates that the resource was not found
 		errorMsg := strings.ToLower(err.Error())
 		if strings.Contains(errorMsg, "does not exist") ||
@@ -1361,8 +1500,30 @@ func (r *GraphqlMutationResource) queryExecuteFramework(ctx context.Context, con
 	return queryExecuteFramework(ctx, config, query, variablesStr, usePagination)
 }
 
-func (r *GraphqlMutationResource) computeMutationVariables(queryResponse string, data *GraphqlMutationResourceModel, dataKeys map[string]interface{}) error {
-	mvks, err := computeMutationVariableKeys(dataKeys, queryResponse)
+// logContext returns a context that masks provider credentials and the values
+// at sensitive_variable_paths in the resource's variables from log output.
+func (r *GraphqlMutationResource) logContext(ctx context.Context, models ...*GraphqlMutationResourceModel) context.Context {
+	var variables []map[string]interface{}
+	for _, data := range models {
+		for _, value := range []types.Dynamic{data.MutationVariables, data.ReadQueryVariables, data.DeleteMutationVariables} {
+			if value.IsNull() || value.IsUnknown() {
+				continue
+			}
+			variablesStr, diags := utils.DynamicToJSONString(ctx, value)
+			if diags.HasError() || variablesStr == "" {
+				continue
+			}
+			var vars map[string]interface{}
+			if err := json.Unmarshal([]byte(variablesStr), &vars); err == nil {
+				variables = append(variables, vars)
+			}
+		}
+	}
+	return r.config.logContext(ctx, variables...)
+}
+
+func (r *GraphqlMutationResource) computeMutationVariables(ctx context.Context, queryResponse string, data *GraphqlMutationResourceModel, dataKeys map[string]interface{}) error {
+	mvks, err := computeMutationVariableKeys(ctx, dataKeys, queryResponse)
 	if err != nil {
 		return err
 	}
@@ -1428,7 +1589,7 @@ func (r *GraphqlMutationResource) prepareUpdatePayload(ctx context.Context, data
 	}
 
 	tflog.Debug(ctx, "Desired mutation variables", map[string]any{
-		"desiredMutationVars": desiredMutationVars,
+		"desiredMutationVars": logValue(desiredMutationVars),
 	})
 
 	// Check if the mutation_variables already contains a patch structure
@@ -1496,7 +1657,7 @@ func (r *GraphqlMutationResource) prepareUpdatePayload(ctx context.Context, data
 					// Extract current state from the query response
 					currentRemoteState = r.extractCurrentStateFromQueryResponse(ctx, queryResponse)
 					tflog.Debug(ctx, "Extracted current remote state", map[string]any{
-						"currentRemoteState": currentRemoteState,
+						"currentRemoteState": logValue(currentRemoteState),
 					})
 				}
 			}
@@ -1507,9 +1668,9 @@ func (r *GraphqlMutationResource) prepareUpdatePayload(ctx context.Context, data
 		updateNeeded := r.isUpdateNeeded(ctx, desiredFields, currentRemoteState)
 
 		tflog.Debug(ctx, "Changed fields (desired vs remote)", map[string]any{
-			"changedFields":      changedFields,
-			"desiredFields":      desiredFields,
-			"currentRemoteState": currentRemoteState,
+			"changedFields":      logValue(changedFields),
+			"desiredFields":      logValue(desiredFields),
+			"currentRemoteState": logValue(currentRemoteState),
 			"updateNeeded":       updateNeeded,
 		})
 
@@ -1602,7 +1763,7 @@ func (r *GraphqlMutationResource) findChangedFields(ctx context.Context, desired
 		// If desired state has a patch structure, use the patch fields for comparison
 		desiredFields = patch
 		tflog.Debug(ctx, "Extracted fields from patch structure", map[string]any{
-			"patchFields": desiredFields,
+			"patchFields": logValue(desiredFields),
 		})
 	}
 
@@ -1613,13 +1774,13 @@ func (r *GraphqlMutationResource) findChangedFields(ctx context.Context, desired
 		if patch, hasPatch := input["patch"].(map[string]interface{}); hasPatch {
 			currentFields = patch
 			tflog.Debug(ctx, "Extracted fields from current patch structure", map[string]any{
-				"currentPatchFields": currentFields,
+				"currentPatchFields": logValue(currentFields),
 			})
 		} else {
 			// Current state has input structure but no patch, use the input fields directly
 			currentFields = input
 			tflog.Debug(ctx, "Using current input fields directly", map[string]any{
-				"currentInputFields": currentFields,
+				"currentInputFields": logValue(currentFields),
 			})
 		}
 	}
@@ -1636,7 +1797,7 @@ func (r *GraphqlMutationResource) findChangedFields(ctx context.Context, desired
 			changedFields[key] = desiredValue
 			tflog.Debug(ctx, "Field added", map[string]any{
 				"field":        key,
-				"desiredValue": desiredValue,
+				"desiredValue": logValue(desiredValue),
 			})
 			continue
 		}
@@ -1646,8 +1807,8 @@ func (r *GraphqlMutationResource) findChangedFields(ctx context.Context, desired
 			changedFields[key] = desiredValue
 			tflog.Debug(ctx, "Field changed", map[string]any{
 				"field":        key,
-				"desiredValue": desiredValue,
-				"currentValue": currentValue,
+				"desiredValue": logValue(desiredValue),
+				"currentValue": logValue(currentValue),
 			})
 		} else {
 			tflog.Debug(ctx, "Field unchanged", map[string]any{
@@ -1670,8 +1831,8 @@ func (r *GraphqlMutationResource) isUpdateNeeded(ctx context.Context, desired, c
 	// we should ignore it since the actual field values are the same
 	if r.isOnlyStructuralChange(desired, current) {
 		tflog.Debug(ctx, "Ignoring structural change in mutation_variables", map[string]any{
-			"desired": desired,
-			"current": current,
+			"desired": logValue(desired),
+			"current": logValue(current),
 		})
 		return false
 	}
@@ -1680,9 +1841,9 @@ func (r *GraphqlMutationResource) isUpdateNeeded(ctx context.Context, desired, c
 
 	tflog.Debug(ctx, "Update need assessment", map[string]any{
 		"hasChanges":    len(changedFields) > 0,
-		"changedFields": changedFields,
-		"desiredFields": desired,
-		"currentFields": current,
+		"changedFields": logValue(changedFields),
+		"desiredFields": logValue(desired),
+		"currentFields": logValue(current),
 	})
 
 	return len(changedFields) > 0
@@ -1796,32 +1957,40 @@ func (r *GraphqlMutationResource) replaceSelfReferences(ctx context.Context, dat
 		}
 	}
 
+	computedKeys := make([]string, 0, len(computedValues))
+	for key := range computedValues {
+		computedKeys = append(computedKeys, key)
+	}
+	sort.Strings(computedKeys)
 	tflog.Debug(ctx, "replaceSelfReferences called", map[string]any{
-		"computedValues": computedValues,
-		"variables":      variables,
+		"computedKeys": computedKeys,
+		"variables":    logValue(r.config.redactVariables(variables)),
 	})
 
 	// Recursively replace self-references
-	result := r.replaceSelfReferencesRecursive(variables, computedValues)
+	result := r.replaceSelfReferencesRecursive(ctx, variables, nil, computedValues)
 	if resultMap, ok := result.(map[string]interface{}); ok {
 		return resultMap
 	}
 	return variables
 }
 
-// replaceSelfReferencesRecursive recursively replaces self-references
-func (r *GraphqlMutationResource) replaceSelfReferencesRecursive(data interface{}, computedValues map[string]string) interface{} {
+// replaceSelfReferencesRecursive recursively replaces self-references.
+// variablePath holds the object keys leading to data, so computed values
+// substituted at sensitive_variable_paths are masked in the log.
+func (r *GraphqlMutationResource) replaceSelfReferencesRecursive(ctx context.Context, data interface{}, variablePath []string, computedValues map[string]string) interface{} {
 	switch v := data.(type) {
 	case map[string]interface{}:
 		newMap := make(map[string]interface{}, len(v))
 		for key, val := range v {
-			newMap[key] = r.replaceSelfReferencesRecursive(val, computedValues)
+			childPath := append(variablePath[:len(variablePath):len(variablePath)], key)
+			newMap[key] = r.replaceSelfReferencesRecursive(ctx, val, childPath, computedValues)
 		}
 		return newMap
 	case []interface{}:
 		newSlice := make([]interface{}, len(v))
 		for i, val := range v {
-			newSlice[i] = r.replaceSelfReferencesRecursive(val, computedValues)
+			newSlice[i] = r.replaceSelfReferencesRecursive(ctx, val, variablePath, computedValues)
 		}
 		return newSlice
 	case string:
@@ -1829,9 +1998,14 @@ func (r *GraphqlMutationResource) replaceSelfReferencesRecursive(data interface{
 		if strings.HasPrefix(v, "self.") {
 			fieldName := strings.TrimPrefix(v, "self.")
 			if value, exists := computedValues[fieldName]; exists {
-				tflog.Debug(context.Background(), "Replacing self-reference with computed value", map[string]any{
-					"self_reference": v,
-					"computed_value": value,
+				loggedValue := logValue(value)
+				if r.config.isSensitiveVariable(variablePath) {
+					ctx = withMaskedValues(ctx, value)
+					loggedValue = redactedValue
+				}
+				tflog.Debug(ctx, "Replacing self-reference with computed value", map[string]any{
+					"self_reference": logValue(v),
+					"computed_value": loggedValue,
 				})
 				return value
 			}
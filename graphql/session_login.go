@@ -0,0 +1,217 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/tidwall/gjson"
+)
+
+// defaultCSRFHeaderName is the header Django expects the CSRF token in.
+const defaultCSRFHeaderName = "X-CSRFToken"
+
+// SessionLoginModel describes the session_login block
+type SessionLoginModel struct {
+	Query          types.String `tfsdk:"query"`
+	Variables      types.Map    `tfsdk:"variables"`
+	CSRFURL        types.String `tfsdk:"csrf_url"`
+	CSRFCookieName types.String `tfsdk:"csrf_cookie_name"`
+	CSRFTokenPath  types.String `tfsdk:"csrf_token_path"`
+	CSRFHeaderName types.String `tfsdk:"csrf_header_name"`
+}
+
+// validateSessionLogin checks that the session_login block is complete.
+func validateSessionLogin(sl *SessionLoginModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !isSet(sl.Query) {
+		diags.AddError(
+			"Incomplete session login configuration",
+			"`query` must be set in the `session_login` block.",
+		)
+	}
+
+	if isSet(sl.CSRFTokenPath) && !isSet(sl.CSRFURL) {
+		diags.AddError(
+			"Incomplete session login configuration",
+			"`csrf_token_path` requires `csrf_url` to be set in the `session_login` block.",
+		)
+	}
+
+	return diags
+}
+
+// csrfHeaderName returns the header the CSRF token is sent in.
+func (sl *SessionLoginModel) csrfHeaderName() string {
+	if isSet(sl.CSRFHeaderName) {
+		return sl.CSRFHeaderName.ValueString()
+	}
+	return defaultCSRFHeaderName
+}
+
+// performSessionLogin runs the login mutation so the server sets a session
+// cookie in the shared client's cookie jar, and returns the CSRF token that
+// must accompany subsequent requests (empty when CSRF is not configured).
+func (p *GraphqlProvider) performSessionLogin(ctx context.Context, config *graphqlProviderConfig, sl *SessionLoginModel) (string, time.Time, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tflog.Debug(ctx, "Performing session login")
+
+	client := config.httpClient()
+	if client.Jar == nil {
+		diags.AddError("Session Login Error", "the HTTP client has no cookie jar; session login requires one.")
+		return "", time.Time{}, diags
+	}
+
+	// Obtain a CSRF token up front, as frameworks like Django reject the login
+	// mutation itself without one.
+	csrfToken, csrfDiags := fetchCSRFToken(ctx, config, sl)
+	diags.Append(csrfDiags...)
+	if diags.HasError() {
+		return "", time.Time{}, diags
+	}
+
+	var variablesJSON string
+	if !sl.Variables.IsNull() && !sl.Variables.IsUnknown() {
+		elements := make(map[string]string)
+		diags.Append(sl.Variables.ElementsAs(ctx, &elements, false)...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
+		}
+
+		resolved, resolveDiags := resolveSecretReferencesInMap(path.Root("session_login").AtName("variables"), elements)
+		diags.Append(resolveDiags...)
+		if diags.HasError() {
+			return "", time.Time{}, diags
+		}
+
+		variablesBytes, err := json.Marshal(resolved)
+		if err != nil {
+			diags.AddError("Session Login Variable Marshaling Error", fmt.Sprintf("failed to marshal session_login variables: %v", err))
+			return "", time.Time{}, diags
+		}
+		variablesJSON = string(variablesBytes)
+	}
+
+	loginConfig := config.withoutAuthentication()
+	// Login variables are credentials, so none of them are logged
+	loginConfig.SensitiveVariablePaths = [][]string{{"*"}}
+	if csrfToken != "" {
+		loginConfig.RequestHeaders = make(map[string]interface{}, len(config.RequestHeaders)+1)
+		for k, v := range config.RequestHeaders {
+			loginConfig.RequestHeaders[k] = v
+		}
+		loginConfig.RequestHeaders[sl.csrfHeaderName()] = csrfToken
+	}
+
+	queryResponse, _, loginDiags := queryExecuteFramework(ctx, loginConfig, sl.Query.ValueString(), variablesJSON, false)
+	diags.Append(loginDiags...)
+	if diags.HasError() {
+		return "", time.Time{}, diags
+	}
+
+	if len(queryResponse.Errors) > 0 {
+		for _, gqlErr := range queryResponse.Errors {
+			diags.AddError("Session Login Error", gqlErr.Message)
+		}
+		return "", time.Time{}, diags
+	}
+
+	// Servers commonly rotate the CSRF token when the session changes
+	if isSet(sl.CSRFCookieName) {
+		if rotated := findCookie(client, sl.CSRFCookieName.ValueString(), config.GQLServerUrl); rotated != "" {
+			csrfToken = rotated
+		}
+	}
+
+	tflog.Debug(ctx, "Session login successful", map[string]any{
+		"csrf": csrfToken != "",
+	})
+	return csrfToken, time.Time{}, diags
+}
+
+// fetchCSRFToken requests csrf_url, if configured, and reads the CSRF token
+// from the response body or from the CSRF cookie.
+func fetchCSRFToken(ctx context.Context, config *graphqlProviderConfig, sl *SessionLoginModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	client := config.httpClient()
+
+	if isSet(sl.CSRFURL) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, sl.CSRFURL.ValueString(), nil)
+		if err != nil {
+			diags.AddError("CSRF Request Creation Error", fmt.Sprintf("failed to create CSRF request: %v", err))
+			return "", diags
+		}
+		for k, v := range config.RequestHeaders {
+			req.Header.Set(k, fmt.Sprintf("%v", v))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			diags.AddError("CSRF Request Error", fmt.Sprintf("failed to request CSRF token: %v", err))
+			return "", diags
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, readDiags := config.readResponse(resp, sl.CSRFURL.ValueString())
+		diags.Append(readDiags...)
+		if diags.HasError() {
+			return "", diags
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			diags.AddError("CSRF HTTP Error", fmt.Sprintf("received HTTP %d from csrf_url", resp.StatusCode))
+			return "", diags
+		}
+
+		if isSet(sl.CSRFTokenPath) {
+			result := gjson.GetBytes(bodyBytes, sl.CSRFTokenPath.ValueString())
+			if !result.Exists() || result.String() == "" {
+				diags.AddError("CSRF Token Extraction Error", fmt.Sprintf("csrf_token_path '%s' not found in CSRF response", sl.CSRFTokenPath.ValueString()))
+				return "", diags
+			}
+			return result.String(), diags
+		}
+	}
+
+	if isSet(sl.CSRFCookieName) {
+		cookieName := sl.CSRFCookieName.ValueString()
+		token := findCookie(client, cookieName, config.GQLServerUrl, sl.CSRFURL.ValueString())
+		if token == "" && isSet(sl.CSRFURL) {
+			diags.AddError("CSRF Cookie Not Found", fmt.Sprintf("csrf_url did not set the %q cookie", cookieName))
+		}
+		return token, diags
+	}
+
+	return "", diags
+}
+
+// findCookie returns the value of the named cookie stored for any of the URLs.
+func findCookie(client *http.Client, name string, urls ...string) string {
+	if client.Jar == nil {
+		return ""
+	}
+	for _, rawURL := range urls {
+		if rawURL == "" {
+			continue
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+		for _, cookie := range client.Jar.Cookies(u) {
+			if cookie.Name == name {
+				return cookie.Value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionTestServer emulates a Django-style backend that issues a CSRF cookie,
+// sets a session cookie on login and rotates the CSRF token afterwards.
+type sessionTestServer struct {
+	mu       sync.Mutex
+	logins   int
+	sessions map[string]string // session id -> csrf token
+}
+
+func (s *sessionTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/csrf" {
+		http.SetCookie(w, &http.Cookie{Name: "csrftoken", Value: "anonymous-csrf", Path: "/"})
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	if strings.Contains(string(body), "login") {
+		if r.Header.Get("X-CSRFToken") != "anonymous-csrf" || !strings.Contains(string(body), `"password":"hunter2"`) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		s.logins++
+		sessionID := fmt.Sprintf("session-%d", s.logins)
+		csrf := fmt.Sprintf("csrf-%d", s.logins)
+		s.sessions[sessionID] = csrf
+		http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: sessionID, Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "csrftoken", Value: csrf, Path: "/"})
+		_, _ = w.Write([]byte(`{"data":{"login":{"ok":true}}}`))
+		return
+	}
+
+	cookie, err := r.Cookie("sessionid")
+	if err != nil || s.sessions[cookie.Value] == "" || s.sessions[cookie.Value] != r.Header.Get("X-CSRFToken") {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"message":"unauthenticated"}]}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"data":{"viewer":{"id":"1"}}}`))
+}
+
+// expireSessions invalidates every session, as a server-side logout would.
+func (s *sessionTestServer) expireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]string{}
+}
+
+func TestSessionLogin(t *testing.T) {
+	backend := &sessionTestServer{sessions: map[string]string{}}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	sessionLogin := &SessionLoginModel{
+		Query: types.StringValue(`mutation login($username: String!, $password: String!) { login(username: $username, password: $password) { ok } }`),
+		Variables: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"username": types.StringValue("admin"),
+			"password": types.StringValue("hunter2"),
+		}),
+		CSRFURL:        types.StringValue(server.URL + "/csrf"),
+		CSRFCookieName: types.StringValue("csrftoken"),
+		CSRFTokenPath:  types.StringNull(),
+		CSRFHeaderName: types.StringNull(),
+	}
+	require.False(t, validateSessionLogin(sessionLogin).HasError())
+
+	p := &GraphqlProvider{}
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL + "/graphql",
+		RequestHeaders: map[string]interface{}{},
+		HTTPClient:     &http.Client{Timeout: defaultHTTPTimeout, Jar: jar},
+		CSRFHeaderName: sessionLogin.csrfHeaderName(),
+	}
+	config.SessionSource = newTokenSource(func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+		return p.performSessionLogin(ctx, config, sessionLogin)
+	})
+
+	csrf, diags := config.SessionSource.Token(context.Background())
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, "csrf-1", csrf, "the rotated CSRF token is used after login")
+
+	queryResponse, _, diags := executeGraphQLRequestFramework(context.Background(), "query { viewer { id } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.NotNil(t, queryResponse.Data["viewer"])
+	assert.Equal(t, 1, backend.logins)
+
+	// An expired session triggers a new login
+	backend.expireSessions()
+	queryResponse, _, diags = executeGraphQLRequestFramework(context.Background(), "query { viewer { id } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.NotNil(t, queryResponse.Data["viewer"])
+	assert.Equal(t, 2, backend.logins)
+}
+
+func TestSessionLogin_ErrorOmitsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprintf(w, "login rejected for request %s", body)
+	}))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	sessionLogin := &SessionLoginModel{
+		Query: types.StringValue(`mutation login($password: String!) { login(password: $password) { ok } }`),
+		Variables: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"password": types.StringValue("hunter2-password"),
+		}),
+		CSRFURL:        types.StringNull(),
+		CSRFCookieName: types.StringNull(),
+		CSRFTokenPath:  types.StringNull(),
+		CSRFHeaderName: types.StringNull(),
+	}
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		HTTPClient:     &http.Client{Timeout: defaultHTTPTimeout, Jar: jar},
+	}
+
+	p := &GraphqlProvider{}
+	_, _, diags := p.performSessionLogin(context.Background(), config, sessionLogin)
+
+	require.True(t, diags.HasError())
+	for _, d := range diags {
+		assert.NotContains(t, d.Detail(), "hunter2-password")
+	}
+	assert.Contains(t, diags[0].Detail(), "HTTP 403")
+}
+
+func TestValidateSessionLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		model       *SessionLoginModel
+		expectError bool
+	}{
+		{
+			name: "query only",
+			model: &SessionLoginModel{
+				Query:         types.StringValue("mutation { login { ok } }"),
+				CSRFURL:       types.StringNull(),
+				CSRFTokenPath: types.StringNull(),
+			},
+		},
+		{
+			name: "missing query",
+			model: &SessionLoginModel{
+				Query:         types.StringNull(),
+				CSRFURL:       types.StringNull(),
+				CSRFTokenPath: types.StringNull(),
+			},
+			expectError: true,
+		},
+		{
+			name: "token path without URL",
+			model: &SessionLoginModel{
+				Query:         types.StringValue("mutation { login { ok } }"),
+				CSRFURL:       types.StringNull(),
+				CSRFTokenPath: types.StringValue("csrfToken"),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectError, validateSessionLogin(tt.model).HasError())
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// isSet reports whether a string attribute has a known, non-empty value.
+func isSet(value types.String) bool {
+	return !value.IsNull() && !value.IsUnknown() && value.ValueString() != ""
+}
+
+// buildTLSConfig builds the TLS configuration shared by the GraphQL and token
+// endpoints. It returns nil when no TLS attributes are configured so the
+// default transport settings are used.
+func buildTLSConfig(data GraphqlProviderModel) (*tls.Config, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	hasClientCert := isSet(data.ClientCert)
+	hasClientKey := isSet(data.ClientKey)
+	insecure := !data.InsecureSkipVerify.IsNull() && !data.InsecureSkipVerify.IsUnknown() && data.InsecureSkipVerify.ValueBool()
+
+	if !isSet(data.CACertPEM) && !isSet(data.CACertFile) && !hasClientCert && !hasClientKey && !insecure {
+		return nil, diags
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if isSet(data.CACertPEM) && isSet(data.CACertFile) {
+		diags.AddError(
+			"Conflicting CA certificate configuration",
+			"Only one of `ca_cert_pem` and `ca_cert_file` may be set.",
+		)
+		return nil, diags
+	}
+
+	if isSet(data.CACertPEM) || isSet(data.CACertFile) {
+		caPEM := []byte(data.CACertPEM.ValueString())
+		attributePath := path.Root("ca_cert_pem")
+		if isSet(data.CACertFile) {
+			attributePath = path.Root("ca_cert_file")
+			contents, err := os.ReadFile(data.CACertFile.ValueString())
+			if err != nil {
+				diags.AddAttributeError(attributePath, "Unable to Read CA Certificate", fmt.Sprintf("failed to read %s: %v", data.CACertFile.ValueString(), err))
+				return nil, diags
+			}
+			caPEM = contents
+		}
+
+		// Trust the system roots as well so a private CA does not break
+		// requests to public token endpoints.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			diags.AddAttributeError(attributePath, "Invalid CA Certificate", "no PEM-encoded certificates could be parsed from the CA bundle.")
+			return nil, diags
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if hasClientCert != hasClientKey {
+		diags.AddError(
+			"Incomplete client certificate configuration",
+			"Both `client_cert` and `client_key` must be set for mutual TLS.",
+		)
+		return nil, diags
+	}
+
+	if hasClientCert {
+		certPEM, certDiags := resolveSecretReferences(path.Root("client_cert"), data.ClientCert.ValueString())
+		diags.Append(certDiags...)
+		keyPEM, keyDiags := resolveSecretReferences(path.Root("client_key"), data.ClientKey.ValueString())
+		diags.Append(keyDiags...)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			diags.AddError("Invalid Client Certificate", fmt.Sprintf("failed to load client certificate and key: %v", err))
+			return nil, diags
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if insecure {
+		diags.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS Certificate Verification Disabled",
+			"insecure_skip_verify is enabled: server certificates for the GraphQL and token endpoints are NOT verified. "+
+				"Traffic, including credentials and tokens, can be intercepted. Never use this outside of local development.",
+		)
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, diags
+}
@@ -0,0 +1,179 @@
+package graphql
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSTestModel returns a provider model with every TLS attribute null.
+func newTLSTestModel() GraphqlProviderModel {
+	return GraphqlProviderModel{
+		CACertPEM:          types.StringNull(),
+		CACertFile:         types.StringNull(),
+		ClientCert:         types.StringNull(),
+		ClientKey:          types.StringNull(),
+		InsecureSkipVerify: types.BoolNull(),
+	}
+}
+
+// generateTestCertificate creates a self-signed client certificate and key in PEM form.
+func generateTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform-provider-graphql-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	certPEM, keyPEM := generateTestCertificate(t)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte(certPEM), 0o600))
+
+	tests := []struct {
+		name          string
+		modify        func(*GraphqlProviderModel)
+		expectNil     bool
+		expectError   bool
+		expectWarning bool
+	}{
+		{
+			name:      "no TLS settings",
+			modify:    func(m *GraphqlProviderModel) {},
+			expectNil: true,
+		},
+		{
+			name:   "CA certificate PEM",
+			modify: func(m *GraphqlProviderModel) { m.CACertPEM = types.StringValue(certPEM) },
+		},
+		{
+			name:   "CA certificate file",
+			modify: func(m *GraphqlProviderModel) { m.CACertFile = types.StringValue(caFile) },
+		},
+		{
+			name: "both CA settings",
+			modify: func(m *GraphqlProviderModel) {
+				m.CACertPEM = types.StringValue(certPEM)
+				m.CACertFile = types.StringValue(caFile)
+			},
+			expectError: true,
+		},
+		{
+			name:        "invalid CA PEM",
+			modify:      func(m *GraphqlProviderModel) { m.CACertPEM = types.StringValue("not a certificate") },
+			expectError: true,
+		},
+		{
+			name: "client certificate and key",
+			modify: func(m *GraphqlProviderModel) {
+				m.ClientCert = types.StringValue(certPEM)
+				m.ClientKey = types.StringValue(keyPEM)
+			},
+		},
+		{
+			name:        "client certificate without key",
+			modify:      func(m *GraphqlProviderModel) { m.ClientCert = types.StringValue(certPEM) },
+			expectError: true,
+		},
+		{
+			name:          "insecure skip verify",
+			modify:        func(m *GraphqlProviderModel) { m.InsecureSkipVerify = types.BoolValue(true) },
+			expectWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTLSTestModel()
+			tt.modify(&model)
+
+			tlsConfig, diags := buildTLSConfig(model)
+			assert.Equal(t, tt.expectError, diags.HasError(), "diagnostics: %v", diags)
+			assert.Equal(t, tt.expectWarning, diags.WarningsCount() > 0)
+			if tt.expectError {
+				return
+			}
+			assert.Equal(t, tt.expectNil, tlsConfig == nil)
+		})
+	}
+}
+
+func TestBuildHTTPClient_MutualTLS(t *testing.T) {
+	certPEM, keyPEM := generateTestCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(certPEM)))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"id":"1"}}}`))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCAPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	model := newTLSTestModel()
+	model.CACertPEM = types.StringValue(string(serverCAPEM))
+	model.ClientCert = types.StringValue(certPEM)
+	model.ClientKey = types.StringValue(keyPEM)
+
+	client, diags := buildHTTPClient(model)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		HTTPClient:     client,
+	}
+	queryResponse, _, _, diags := executeSingleGraphQLRequest(context.Background(), "query { viewer { id } }", nil, config)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.NotNil(t, queryResponse.Data["viewer"])
+
+	// Without the client certificate the handshake is rejected
+	model.ClientCert = types.StringNull()
+	model.ClientKey = types.StringNull()
+	client, diags = buildHTTPClient(model)
+	require.False(t, diags.HasError())
+	config.HTTPClient = client
+	_, _, _, diags = executeSingleGraphQLRequest(context.Background(), "query { viewer { id } }", nil, config)
+	assert.True(t, diags.HasError())
+}
@@ -0,0 +1,155 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// tokenRefreshSkew is how long before expiry a token is proactively refreshed.
+const tokenRefreshSkew = 60 * time.Second
+
+// tokenFetchFunc obtains a fresh access token. A zero expiry means the token
+// lifetime is unknown and it is only refreshed after being rejected. The token
+// may be empty for logins that only establish server-side state (e.g. a
+// session cookie).
+type tokenFetchFunc func(ctx context.Context) (string, time.Time, diag.Diagnostics)
+
+// tokenSource caches a bearer token and transparently logs in again when the
+// token is about to expire or has been rejected by the server.
+type tokenSource struct {
+	mu        sync.Mutex
+	fetch     tokenFetchFunc
+	valid     bool
+	token     string
+	expiry    time.Time
+	refreshAt time.Time
+	fetchedAt time.Time
+	now       func() time.Time
+}
+
+// newTokenSource creates a token source backed by the given login function.
+func newTokenSource(fetch tokenFetchFunc) *tokenSource {
+	return &tokenSource{
+		fetch: fetch,
+		now:   time.Now,
+	}
+}
+
+// Token returns a valid access token, logging in again if the cached token is
+// missing or close to expiry. Concurrent callers share a single login.
+func (s *tokenSource) Token(ctx context.Context) (string, diag.Diagnostics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.valid && (s.refreshAt.IsZero() || now.Before(s.refreshAt)) {
+		return s.token, nil
+	}
+
+	if s.valid {
+		tflog.Debug(ctx, "Access token is about to expire, refreshing", map[string]any{
+			"expiry": s.expiry,
+		})
+	}
+
+	token, expiry, diags := s.fetch(ctx)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	s.valid = true
+	s.token = token
+	s.expiry = expiry
+	s.fetchedAt = now
+	s.refreshAt = time.Time{}
+	if !expiry.IsZero() {
+		// Refresh ahead of expiry, but never so early that short-lived tokens
+		// are fetched on every request.
+		skew := tokenRefreshSkew
+		if lifetime := expiry.Sub(now); lifetime < 2*skew {
+			skew = lifetime / 2
+		}
+		s.refreshAt = expiry.Add(-skew)
+	}
+
+	return token, diags
+}
+
+// cachedToken returns the current token without logging in, or an empty
+// string if there is none.
+func (s *tokenSource) cachedToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.token
+}
+
+// Expire discards the cached token if it was obtained before the given time,
+// forcing the next call to Token to log in again. Requests that were rejected
+// after another caller already refreshed the token do not trigger a new login.
+func (s *tokenSource) Expire(issuedBefore time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fetchedAt.Before(issuedBefore) {
+		s.valid = false
+		s.token = ""
+		s.expiry = time.Time{}
+		s.refreshAt = time.Time{}
+	}
+}
+
+// parseTokenExpiry converts an expiry value from a login response into an
+// absolute time. Numbers are treated as seconds from now (as in OAuth2
+// expires_in) and strings as RFC 3339 timestamps.
+func parseTokenExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return time.Time{}, nil
+		}
+		return now.Add(time.Duration(seconds * float64(time.Second))), nil
+	}
+
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("token expiry %q is neither a number of seconds nor an RFC 3339 timestamp", value)
+	}
+	return expiry, nil
+}
+
+// parseAbsoluteTokenExpiry converts an expiry timestamp into a time. Numbers
+// are treated as Unix timestamps in seconds (as in expires_at) and strings as
+// RFC 3339 timestamps.
+func parseAbsoluteTokenExpiry(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return time.Time{}, nil
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+	}
+
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("token expiry %q is neither a Unix timestamp nor an RFC 3339 timestamp", value)
+	}
+	return expiry, nil
+}
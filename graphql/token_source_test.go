@@ -0,0 +1,238 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	gqlerrors "github.com/kalenarndt/terraform-provider-graphql/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	fetches := 0
+
+	source := newTokenSource(func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+		fetches++
+		return fmt.Sprintf("token-%d", fetches), now.Add(10 * time.Minute), nil
+	})
+	source.now = func() time.Time { return now }
+
+	token, diags := source.Token(context.Background())
+	require.False(t, diags.HasError())
+	assert.Equal(t, "token-1", token)
+
+	// Still well within the token lifetime
+	now = now.Add(5 * time.Minute)
+	token, _ = source.Token(context.Background())
+	assert.Equal(t, "token-1", token)
+
+	// Inside the refresh skew window
+	now = now.Add(4*time.Minute + 30*time.Second)
+	token, _ = source.Token(context.Background())
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestTokenSource_Expire(t *testing.T) {
+	fetches := 0
+	source := newTokenSource(func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+		fetches++
+		return fmt.Sprintf("token-%d", fetches), time.Time{}, nil
+	})
+
+	before := time.Now()
+	token, _ := source.Token(context.Background())
+	assert.Equal(t, "token-1", token)
+
+	// A rejection from a request that started before the token was issued is ignored
+	source.Expire(before)
+	token, _ = source.Token(context.Background())
+	assert.Equal(t, "token-1", token)
+
+	source.Expire(time.Now().Add(time.Second))
+	token, _ = source.Token(context.Background())
+	assert.Equal(t, "token-2", token)
+}
+
+func TestParseTokenExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{
+			name:     "empty value",
+			value:    "",
+			expected: time.Time{},
+		},
+		{
+			name:     "seconds until expiry",
+			value:    "3600",
+			expected: now.Add(time.Hour),
+		},
+		{
+			name:     "RFC 3339 timestamp",
+			value:    "2024-01-01T13:30:00Z",
+			expected: time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:        "invalid value",
+			value:       "tomorrow",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseTokenExpiry(tt.value, now)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %v, got %v", tt.expected, result)
+		})
+	}
+}
+
+func TestParseAbsoluteTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{
+			name:     "empty value",
+			value:    "",
+			expected: time.Time{},
+		},
+		{
+			name:     "Unix timestamp",
+			value:    "1704115800",
+			expected: time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "RFC 3339 timestamp",
+			value:    "2024-01-01T13:30:00Z",
+			expected: time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:        "invalid value",
+			value:       "tomorrow",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseAbsoluteTokenExpiry(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %v, got %v", tt.expected, result)
+		})
+	}
+}
+
+func TestExecuteGraphQLRequestFramework_ReauthenticatesOn401(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"token expired"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"todo":{"id":"1"}}}`))
+	}))
+	defer server.Close()
+
+	fetches := 0
+	config := &graphqlProviderConfig{
+		GQLServerUrl:   server.URL,
+		RequestHeaders: map[string]interface{}{},
+		TokenSource: newTokenSource(func(ctx context.Context) (string, time.Time, diag.Diagnostics) {
+			fetches++
+			return fmt.Sprintf("token-%d", fetches), time.Time{}, nil
+		}),
+	}
+	_, _ = config.TokenSource.Token(context.Background())
+
+	queryResponse, _, diags := executeGraphQLRequestFramework(context.Background(), "query { todo { id } }", nil, config)
+
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, 2, fetches)
+	assert.NotNil(t, queryResponse.Data["todo"])
+}
+
+func TestIsAuthenticationError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		queryResponse *GqlQueryResponse
+		expected      bool
+	}{
+		{
+			name:     "HTTP 401",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusUnauthorized, Body: "Unauthorized"},
+			expected: true,
+		},
+		{
+			name:     "wrapped HTTP 401",
+			err:      fmt.Errorf("login failed: %w", &gqlerrors.RequestError{StatusCode: http.StatusUnauthorized}),
+			expected: true,
+		},
+		{
+			name:     "no request error",
+			err:      (*gqlerrors.RequestError)(nil),
+			expected: false,
+		},
+		{
+			name:          "UNAUTHENTICATED error code",
+			queryResponse: &GqlQueryResponse{Errors: []GqlError{{Message: "denied", Extensions: map[string]interface{}{"code": "UNAUTHENTICATED"}}}},
+			expected:      true,
+		},
+		{
+			name:          "TOKEN_EXPIRED error code",
+			queryResponse: &GqlQueryResponse{Errors: []GqlError{{Message: "JWT token expired", Extensions: map[string]interface{}{"code": "token_expired"}}}},
+			expected:      true,
+		},
+		{
+			name:          "expired token message without code",
+			queryResponse: &GqlQueryResponse{Errors: []GqlError{{Message: "JWT token expired"}}},
+			expected:      false,
+		},
+		{
+			name:          "permission error",
+			queryResponse: &GqlQueryResponse{Errors: []GqlError{{Message: "Unauthorized to access field secret", Extensions: map[string]interface{}{"code": "FORBIDDEN"}}}},
+			expected:      false,
+		},
+		{
+			name:          "unrelated GraphQL error",
+			queryResponse: &GqlQueryResponse{Errors: []GqlError{{Message: "field not found"}}},
+			expected:      false,
+		},
+		{
+			name:     "other HTTP error",
+			err:      &gqlerrors.RequestError{StatusCode: http.StatusInternalServerError, Body: "HTTP 401 upstream"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isAuthenticationError(tt.err, tt.queryResponse))
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package graphql
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// WebSocket opcodes (RFC 6455, section 5.2).
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// webSocketGUID is appended to the client key to compute the accept key.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWebSocketMessageSize bounds the size of a received message.
+const maxWebSocketMessageSize = 16 << 20
+
+// webSocketConn is a minimal client-side WebSocket connection, sufficient for
+// the text messages of GraphQL subscription protocols.
+type webSocketConn struct {
+	conn io.ReadWriteCloser
+	r    *bufio.Reader
+	// mask is set for client connections, whose frames must be masked
+	mask bool
+
+	writeMu sync.Mutex
+}
+
+// webSocketHandshakeURL returns the HTTP URL the handshake for a ws://, wss://,
+// http:// or https:// endpoint is sent to.
+func webSocketHandshakeURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http", "ws":
+		u.Scheme = "http"
+	case "https", "wss":
+		u.Scheme = "https"
+	default:
+		return "", fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// webSocketAcceptKey returns the Sec-WebSocket-Accept value for a key.
+func webSocketAcceptKey(key string) string {
+	digest := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// dialWebSocket opens a WebSocket connection through the provider's HTTP
+// client, so its TLS, proxy and cookie settings apply, with the provider's
+// credentials and headers on the handshake.
+func dialWebSocket(ctx context.Context, endpoint, subprotocol string, config *graphqlProviderConfig) (*webSocketConn, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	requestURL, err := webSocketHandshakeURL(endpoint)
+	if err != nil {
+		diags.AddError("WebSocket Error", fmt.Sprintf("invalid WebSocket URL %q: %v", endpoint, err))
+		return nil, diags
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		diags.AddError("WebSocket Error", fmt.Sprintf("failed to create handshake request: %v", err))
+		return nil, diags
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		diags.AddError("WebSocket Error", fmt.Sprintf("failed to generate handshake key: %v", err))
+		return nil, diags
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	diags.Append(setAuthHeaders(ctx, req, config)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Protocol", subprotocol)
+	if config.Signer != nil {
+		if err := config.Signer.Sign(ctx, req, nil); err != nil {
+			diags.AddError("AWS Credentials Error", err.Error())
+			return nil, diags
+		}
+	}
+
+	// The client timeout would bound the whole connection and hide the
+	// writable body of the upgraded response; the context bounds it instead
+	client := *config.httpClient()
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		diags.AddError("WebSocket Error", fmt.Sprintf("failed to connect to %s: %v", endpoint, err))
+		return nil, diags
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		resp.Body.Close()
+		diags.AddError("WebSocket Error", fmt.Sprintf("server did not upgrade the connection: HTTP %d: %s", resp.StatusCode, summarizeBody(resp.Header.Get("Content-Type"), body)))
+		return nil, diags
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		diags.AddError("WebSocket Error", "upgraded connection is not writable")
+		return nil, diags
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAcceptKey(key) {
+		conn.Close()
+		diags.AddError("WebSocket Error", "server sent an invalid Sec-WebSocket-Accept header")
+		return nil, diags
+	}
+	if protocol := resp.Header.Get("Sec-WebSocket-Protocol"); protocol != subprotocol {
+		conn.Close()
+		diags.AddError("WebSocket Error", fmt.Sprintf("server does not support the %s protocol (selected %q)", subprotocol, protocol))
+		return nil, diags
+	}
+
+	return newWebSocketConn(conn, true), diags
+}
+
+// newWebSocketConn wraps an upgraded connection.
+func newWebSocketConn(conn io.ReadWriteCloser, mask bool) *webSocketConn {
+	return &webSocketConn{conn: conn, r: bufio.NewReader(conn), mask: mask}
+}
+
+// WriteText sends a text message.
+func (c *webSocketConn) WriteText(message []byte) error {
+	return c.writeFrame(wsOpText, message)
+}
+
+// writeFrame sends a single, final frame.
+func (c *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	header := []byte{0x80 | opcode, 0}
+	switch {
+	case len(payload) < 126:
+		header[1] = byte(len(payload))
+	case len(payload) <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+
+	frame := payload
+	if c.mask {
+		header[1] |= 0x80
+		maskKey := make([]byte, 4)
+		if _, err := rand.Read(maskKey); err != nil {
+			return err
+		}
+		header = append(header, maskKey...)
+		frame = make([]byte, len(payload))
+		for i := range payload {
+			frame[i] = payload[i] ^ maskKey[i%4]
+		}
+	}
+
+	_, err := c.conn.Write(append(header, frame...))
+	return err
+}
+
+// ReadMessage returns the next text or binary message, answering pings and
+// reassembling fragmented messages. It returns io.EOF when the peer closes
+// the connection.
+func (c *webSocketConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		final, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+		case wsOpPong:
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, payload)
+			if len(payload) > 2 {
+				return nil, fmt.Errorf("connection closed by server: %d %s", binary.BigEndian.Uint16(payload), payload[2:])
+			}
+			return nil, io.EOF
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			if len(message)+len(payload) > maxWebSocketMessageSize {
+				return nil, fmt.Errorf("message exceeds %d bytes", maxWebSocketMessageSize)
+			}
+			message = append(message, payload...)
+			if final {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unexpected WebSocket opcode %d", opcode)
+		}
+	}
+}
+
+// readFrame reads a single frame, unmasking its payload.
+func (c *webSocketConn) readFrame() (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return false, 0, nil, err
+	}
+	final := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.r, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.r, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if length > maxWebSocketMessageSize {
+		return false, 0, nil, fmt.Errorf("frame exceeds %d bytes", maxWebSocketMessageSize)
+	}
+
+	var maskKey []byte
+	if masked {
+		maskKey = make([]byte, 4)
+		if _, err := io.ReadFull(c.r, maskKey); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+	return final, opcode, payload, nil
+}
+
+// Close sends a normal closure frame and closes the connection.
+func (c *webSocketConn) Close() error {
+	_ = c.writeFrame(wsOpClose, []byte{0x03, 0xE8})
+	return c.conn.Close()
+}
@@ -2,9 +2,13 @@ package errors
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"strings"
+	"syscall"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -31,6 +35,37 @@ type HTTPError struct {
 	URL        string
 }
 
+// RequestError describes a failed request to the GraphQL server. It carries
+// the response status, headers and any GraphQL errors in the body so the
+// failure can be classified and retried without inspecting message strings.
+type RequestError struct {
+	StatusCode    int
+	Header        http.Header
+	Body          string
+	URL           string
+	GraphQLErrors []GraphQLError
+	// Err is the transport error when no response was received
+	Err error
+}
+
+// Error implements the error interface
+func (e *RequestError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("request to %s failed: %v", e.URL, e.Err)
+	}
+	return fmt.Sprintf("HTTP %d error from %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// Unwrap returns the underlying transport error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Type classifies the error using ClassifyError
+func (e *RequestError) Type() string {
+	return ClassifyError(e.Err, e.StatusCode, e.GraphQLErrors)
+}
+
 // Error types for classification
 const (
 	ErrorTypeNetwork    = "network"
@@ -110,13 +145,8 @@ func ClassifyError(err error, statusCode int, graphqlErrors []GraphQLError) stri
 	}
 
 	// Check for network errors
-	if err != nil {
-		errMsg := strings.ToLower(err.Error())
-		if strings.Contains(errMsg, "timeout") ||
-			strings.Contains(errMsg, "connection refused") ||
-			strings.Contains(errMsg, "no route to host") {
-			return ErrorTypeNetwork
-		}
+	if IsTransientNetworkError(err) {
+		return ErrorTypeNetwork
 	}
 
 	return ErrorTypeBusiness
@@ -188,3 +218,51 @@ func IsRetryableStatusCode(statusCode int) bool {
 	return statusCode == 429 || // Rate limit
 		statusCode >= 500 && statusCode < 600 // Server errors
 }
+
+// IsTransientStatusCode checks if an HTTP status code indicates a temporary
+// gateway or availability problem that is likely to clear on its own
+func IsTransientStatusCode(statusCode int) bool {
+	return statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
+}
+
+// IsTransientNetworkError checks if an error is a network failure, such as a
+// timeout or a refused or reset connection, that may succeed when retried
+func IsTransientNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if IsConnectError(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "timeout") ||
+		strings.Contains(errMsg, "connection refused") ||
+		strings.Contains(errMsg, "connection reset") ||
+		strings.Contains(errMsg, "no route to host")
+}
+
+// IsConnectError checks if an error happened while establishing the
+// connection, in which case the request never reached the server
+func IsConnectError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
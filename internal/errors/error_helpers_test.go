@@ -1,7 +1,12 @@
 package errors
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"syscall"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -463,3 +468,126 @@ func TestIsRetryableStatusCode(t *testing.T) {
 		})
 	}
 }
+
+func TestIsTransientStatusCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		expected   bool
+	}{
+		{
+			name:       "bad gateway",
+			statusCode: 502,
+			expected:   true,
+		},
+		{
+			name:       "service unavailable",
+			statusCode: 503,
+			expected:   true,
+		},
+		{
+			name:       "gateway timeout",
+			statusCode: 504,
+			expected:   true,
+		},
+		{
+			name:       "internal server error",
+			statusCode: 500,
+			expected:   false,
+		},
+		{
+			name:       "rate limit",
+			statusCode: 429,
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsTransientStatusCode(tt.statusCode)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestIsTransientNetworkError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "connection refused",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			expected: true,
+		},
+		{
+			name:     "connection reset",
+			err:      fmt.Errorf("read: %w", syscall.ECONNRESET),
+			expected: true,
+		},
+		{
+			name:     "unexpected EOF",
+			err:      fmt.Errorf("reading response: %w", io.ErrUnexpectedEOF),
+			expected: true,
+		},
+		{
+			name:     "timeout",
+			err:      context.DeadlineExceeded,
+			expected: true,
+		},
+		{
+			name:     "other error",
+			err:      assert.AnError,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsTransientNetworkError(tt.err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          *RequestError
+		expectedType string
+		expectedMsg  string
+	}{
+		{
+			name:         "HTTP status",
+			err:          &RequestError{StatusCode: 503, URL: "https://api.example.com/graphql", Body: "unavailable"},
+			expectedType: ErrorTypeNetwork,
+			expectedMsg:  "HTTP 503 error from https://api.example.com/graphql: unavailable",
+		},
+		{
+			name:         "transport error",
+			err:          &RequestError{URL: "https://api.example.com/graphql", Err: syscall.ECONNREFUSED},
+			expectedType: ErrorTypeNetwork,
+			expectedMsg:  "request to https://api.example.com/graphql failed: connection refused",
+		},
+		{
+			name:         "GraphQL errors",
+			err:          &RequestError{StatusCode: 400, GraphQLErrors: []GraphQLError{{Message: "invalid query"}}},
+			expectedType: ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedType, tt.err.Type())
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, tt.err.Error())
+			}
+		})
+	}
+}